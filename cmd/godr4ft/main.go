@@ -4,18 +4,64 @@ import (
 	"flag"
 	"github.com/malexanderboyd/pwr9-godr4ft/internal/director"
 	"log"
+	"os"
+	"strings"
+	"time"
 )
 
-
 func main() {
 	log.SetFlags(log.Lshortfile)
 
 	port := flag.Int("port", 8000, "the port the server will open a socket server on")
-	gameId := flag.String("gameId", "", "Four byte url safe hex string")
+	gameId := flag.String("gameId", "", "Four byte url safe hex string, optional, other games are created when players join /game/{id}/ws")
+	snapshot := flag.String("snapshot", "", "file the draft is saved to after every round, defaults to a file in snapshotDir")
+	snapshotDir := flag.String("snapshotDir", "", "directory games are saved to and correspondence drafts are resumed from, defaults to the temp dir")
+	resume := flag.String("resume", "", "snapshot file to resume a draft from after a restart")
+	gracePeriod := flag.Duration("gracePeriod", 10*time.Minute, "how long a game stays up after it ends so players can export their pools")
+	lobbyIdleTimeout := flag.Duration("lobbyIdleTimeout", director.LobbyIdleTimeout, "how long a lobby nobody is connected to stays up")
+	abandonedGameTimeout := flag.Duration("abandonedGameTimeout", director.AbandonedGameTimeout, "how long a started game everyone has left stays up, correspondence drafts stay up regardless")
+	finishedGameTTL := flag.Duration("finishedGameTTL", director.FinishedGameTTL, "how long a finished game's id is answered with 410 Gone instead of starting it again")
+	mtgjsonDir := flag.String("mtgjson", "", "directory of MTGJSON set files and games/{id}.json options to run drafts from instead of the API")
+	mtgjsonFallback := flag.String("mtgjsonFallback", "", "directory of MTGJSON set files to generate boosters from when the API has none for a set")
+	notifyWebhook := flag.String("notifyWebhook", "", "url correspondence drafters' pack waiting notices are posted to, they are only logged without it")
+	allowedOrigins := flag.String("allowedOrigins", "", "comma separated origins browsers may connect from, ie: https://draft.example.com, any origin when empty")
+	chatLimitsByAddress := flag.Bool("chatLimitsByAddress", false, "carry chat mutes and rate limits over to anyone joining from the same address, off behind a shared proxy or NAT")
 	flag.Parse()
 
-	director.StartDraftServer(*gameId, *port)
-}
-
-
+	var cardSource director.CardSource
+	if *mtgjsonDir != "" {
+		cardSource = director.NewMTGJSONCardSource(*mtgjsonDir)
+	}
+	var boosterFallback director.CardSource
+	if *mtgjsonFallback != "" {
+		boosterFallback = director.NewMTGJSONCardSource(*mtgjsonFallback)
+	}
+	var notifier director.Notifier
+	if *notifyWebhook != "" {
+		notifier = director.NewWebhookNotifier(*notifyWebhook)
+	}
+	var origins []string
+	if *allowedOrigins != "" {
+		origins = strings.Split(*allowedOrigins, ",")
+	}
 
+	err := director.StartDraftServer(director.ServerConfig{
+		GameId:               *gameId,
+		Port:                 *port,
+		SnapshotPath:         *snapshot,
+		SnapshotDir:          *snapshotDir,
+		ResumePath:           *resume,
+		EndGracePeriod:       *gracePeriod,
+		LobbyIdleTimeout:     *lobbyIdleTimeout,
+		AbandonedGameTimeout: *abandonedGameTimeout,
+		FinishedGameTTL:      *finishedGameTTL,
+		CardSource:           cardSource,
+		BoosterFallback:      boosterFallback,
+		Notifier:             notifier,
+		// kept out of the flags so it never shows up in the process list
+		JoinSecret:          []byte(os.Getenv("GODR4FT_JOIN_SECRET")),
+		AllowedOrigins:      origins,
+		ChatLimitsByAddress: *chatLimitsByAddress,
+	})
+	log.Fatal(err)
+}
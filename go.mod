@@ -4,6 +4,8 @@ go 1.13
 
 require (
 	github.com/gorilla/websocket v1.4.1
+	github.com/prometheus/client_golang v1.4.1
+	github.com/prometheus/client_model v0.2.0
 	go.uber.org/atomic v1.5.1 // indirect
 	go.uber.org/multierr v1.4.0 // indirect
 	go.uber.org/zap v1.13.0
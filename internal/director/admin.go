@@ -0,0 +1,187 @@
+package director
+
+import (
+	"errors"
+	"fmt"
+	"github.com/malexanderboyd/pwr9-godr4ft/internal"
+	"github.com/malexanderboyd/pwr9-godr4ft/internal/director/models"
+	"time"
+)
+
+// most a single extend_round can add to a round
+const MaxRoundExtension = 10 * time.Minute
+
+// handleHostCommand runs a host only command, HandleClientMessage has already
+// checked the sender is the host
+func (director *GameDirector) handleHostCommand(clientID string, command models.GameMessageType, payload interface{}) error {
+	logger := internal.GetLogger()
+	logger.Infow("Host command", "game", director.GameId, "host", clientID, "command", command)
+	switch command {
+	case models.KickPlayer:
+		kickMsg := payload.(*models.KickPlayerJson)
+		if kickMsg.PlayerId == clientID {
+			return errors.New("the host cannot kick themselves")
+		}
+		if !director.isExistingClient(kickMsg.PlayerId) {
+			return errors.New(fmt.Sprintf("no player with id: %s", kickMsg.PlayerId))
+		}
+		if director.phase == models.PhaseTournament {
+			// a bot can't play their matches, the round would never finish
+			return newClientError(models.ErrWrongPhase, "players cannot be kicked once the tournament has started")
+		}
+		director.kickPlayer(kickMsg.PlayerId)
+	case models.PauseTimer, models.ResumeTimer:
+		if director.phase != models.PhaseDrafting {
+			return errors.New("the pick timer only runs while drafting")
+		}
+		director.adminMu.Lock()
+		director.roundTimerPaused = command == models.PauseTimer
+		director.adminMu.Unlock()
+		director.sendRoundTimers()
+	case models.ExtendRound:
+		if director.phase != models.PhaseDrafting {
+			return errors.New("there is no round to extend")
+		}
+		extendMsg := payload.(*models.ExtendRoundJson)
+		extension := time.Duration(extendMsg.Seconds) * time.Second
+		if extension <= 0 || extension > MaxRoundExtension {
+			return errors.New(fmt.Sprintf("invalid round extension %d", extendMsg.Seconds))
+		}
+		// every pack players are holding right now gets the extra time
+		director.pickMu.Lock()
+		for _, seat := range director.getSeatsHoldingPacks() {
+			director.getPickTimer(seat).extension += extension
+		}
+		director.pickMu.Unlock()
+		director.sendRoundTimers()
+	case models.ForceAdvance:
+		if director.phase != models.PhaseDrafting {
+			return errors.New("there is no round to advance")
+		}
+		director.adminMu.Lock()
+		director.forceAdvance = true
+		director.adminMu.Unlock()
+	case models.EndGameEarly:
+		go director.shutdown()
+	case models.MutePlayer:
+		return director.handleHostMutePlayer(clientID, payload.(*models.MutePlayerJson))
+	case models.RetractChat:
+		return director.retractChat(payload.(*models.RetractChatJson).MessageId)
+	default:
+		return errors.New(fmt.Sprintf("unknown host command %s", command))
+	}
+	return nil
+}
+
+func (director *GameDirector) isKicked(clientID string) bool {
+	director.adminMu.Lock()
+	defer director.adminMu.Unlock()
+	return director.kicked[clientID]
+}
+
+// kickPlayer removes a player from the game, a bot takes over their seat and
+// pool. It runs on the Listen loop since it changes who is in the game.
+func (director *GameDirector) kickPlayer(clientID string) {
+	logger := internal.GetLogger()
+	client, ok := director.Clients[clientID]
+	if !ok {
+		return
+	}
+
+	director.adminMu.Lock()
+	director.kicked[clientID] = true
+	director.adminMu.Unlock()
+	delete(director.Clients, clientID)
+	director.removeFromSeatOrder(clientID)
+
+	if seat, seated := director.Seats[clientID]; seated {
+		strategy, err := NewBotStrategy(director.options.BotStrategy, director.rng)
+		if err != nil {
+			strategy, _ = NewBotStrategy(DefaultBotStrategy, director.rng)
+		}
+		bot := NewBot(director, seat, strategy)
+		bot.pool = client.getPool()
+		director.pickMu.Lock()
+		director.Bots[bot.Id] = bot
+		delete(director.Seats, clientID)
+		director.Seats[bot.Id] = seat
+		director.pickMu.Unlock()
+		logger.Infow("Kicked player, bot took their seat", "game", director.GameId, "client", clientID, "bot", bot.Id, "seat", seat)
+
+		if director.phase == models.PhaseDrafting {
+			// the bot works through the packs queued for the kicked player
+			director.pickCardsForBots()
+		}
+	} else {
+		logger.Infow("Kicked player", "game", director.GameId, "client", clientID)
+	}
+
+	client.Write(models.NewMessage(models.Kicked, clientID))
+	go func() {
+		// let the kicked message go out before the connection closes
+		time.Sleep(time.Second)
+		client.Done()
+	}()
+	director.sendAll(models.NewMessage(models.NewPlayer, director.getConnectedClientCount()))
+	director.sendAll(director.getRosterMessage())
+	if director.phase != models.PhaseLobby {
+		// a lobby restores as a game that already started, it isn't saved
+		director.saveSnapshot()
+	}
+}
+
+// tickPickTimers moves every held pack's timer on a second unless the host
+// paused them. It returns the seats that have to be picked for now, which is
+// every seat holding a pack once the host forces the draft on.
+func (director *GameDirector) tickPickTimers() []int {
+	director.adminMu.Lock()
+	paused := director.roundTimerPaused
+	force := director.forceAdvance
+	director.forceAdvance = false
+	director.adminMu.Unlock()
+
+	director.pickMu.Lock()
+	defer director.pickMu.Unlock()
+	var due []int
+	for _, seat := range director.getSeatsHoldingPacks() {
+		if !paused {
+			director.getPickTimer(seat).elapsed += time.Second
+		} else if director.isCorrespondence() {
+			// correspondence timers run on the wall clock, a pause pushes the deadline back
+			director.getPickTimer(seat).extension += time.Second
+		}
+		timesUp := director.isTimerEnabled() && director.isServerForcePickEnabled() && director.getPickTimeRemaining(seat) == 0
+		if force || timesUp {
+			due = append(due, seat)
+		}
+	}
+	return due
+}
+
+func (director *GameDirector) isRoundTimerPaused() bool {
+	director.adminMu.Lock()
+	defer director.adminMu.Unlock()
+	return director.roundTimerPaused
+}
+
+func (director *GameDirector) getRoundTimerMessage(seat int) *models.Message {
+	return models.NewMessage(models.RoundTimer, &models.RoundTimerJson{
+		Seconds: int(director.getPickTimeRemaining(seat) / time.Second),
+		Paused:  director.isRoundTimerPaused(),
+	})
+}
+
+// sendRoundTimers tells every player whether the timer is held and how long
+// they have left on the pack in front of them, players still waiting on a
+// pack see no time left
+func (director *GameDirector) sendRoundTimers() {
+	director.pickMu.Lock()
+	defer director.pickMu.Unlock()
+	for clientID, client := range director.Clients {
+		seat, seated := director.Seats[clientID]
+		if !seated {
+			seat = -1
+		}
+		client.Write(director.getRoundTimerMessage(seat))
+	}
+}
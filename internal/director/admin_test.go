@@ -0,0 +1,118 @@
+package director_test
+
+import (
+	"github.com/gorilla/websocket"
+	"github.com/malexanderboyd/pwr9-godr4ft/internal/director"
+	"github.com/malexanderboyd/pwr9-godr4ft/internal/director/models"
+	"github.com/malexanderboyd/pwr9-godr4ft/internal/game"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHostCommands(t *testing.T) {
+	var options game.GeneralOptions
+	d := director.NewGameDirector(options, 9000, "admin_game")
+	go d.Listen()
+	defer d.Finish()
+
+	ts := httptest.NewServer(d.Handler())
+	defer ts.Close()
+	wsUrl := "ws" + strings.TrimPrefix(ts.URL, "http") + "/ws"
+
+	host, _, err := websocket.DefaultDialer.Dial(wsUrl, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer host.Close()
+	director.ReadUntil(t, host, models.HostChange)
+
+	player, res, err := websocket.DefaultDialer.Dial(wsUrl, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer player.Close()
+	playerCookie := res.Header.Get("Set-Cookie")
+	director.ReadUntil(t, player, models.NewPlayer)
+
+	_ = player.WriteJSON(&models.Message{Type: models.PauseTimer})
+	if code := director.ReadError(t, player); code != models.ErrUnauthorized {
+		t.Errorf("expected a not the host error, got %q", code)
+	}
+
+	d.RunOnLoop(func() { d.SetPhase(models.PhaseDrafting) })
+	_ = host.WriteJSON(&models.Message{Type: models.PauseTimer})
+	var timer models.RoundTimerJson
+	_ = director.ReadUntil(t, player, models.RoundTimer).Decode(&timer)
+	if !timer.Paused {
+		t.Errorf("expected the timer to be paused")
+	}
+	var before, after time.Duration
+	var due []int
+	var playerID string
+	d.RunOnLoop(func() {
+		before = d.GetPickTimeRemaining(0)
+		due = d.TickPickTimers()
+		after = d.GetPickTimeRemaining(0)
+		for id := range d.Clients {
+			if id != d.Host() {
+				playerID = id
+			}
+		}
+	})
+	if len(due) != 0 || after != before {
+		t.Errorf("expected a paused timer to hold its time, seats %v are due", due)
+	}
+	_ = host.WriteJSON(models.NewMessage(models.KickPlayer, &models.KickPlayerJson{PlayerId: playerID}))
+	director.ReadUntil(t, player, models.Kicked)
+
+	header := http.Header{}
+	header.Set("Cookie", strings.Split(playerCookie, ";")[0])
+	if _, res, err := websocket.DefaultDialer.Dial(wsUrl, header); err == nil || res.StatusCode != http.StatusForbidden {
+		t.Errorf("expected a kicked player to be turned away")
+	}
+}
+
+func TestKickedPlayerSeatGoesToBot(t *testing.T) {
+	d, clients := director.NewSeatedCubeDraft(t, "kick_game", game.GeneralOptions{}, 1)
+	client := clients[0]
+	d.DealPack()
+
+	d.KickPlayer(client.Id)
+	if _, ok := d.Clients[client.Id]; ok {
+		t.Errorf("expected the kicked player to be removed")
+	}
+	if len(d.Bots) != 2 || len(d.Seats) != 2 {
+		t.Fatalf("expected two bots in two seats, got %d bots %d seats", len(d.Bots), len(d.Seats))
+	}
+	if picks := d.PickLog(); len(picks) != 6 {
+		t.Errorf("expected the bots to draft the whole pack once the player is gone, got %d picks", len(picks))
+	}
+}
+
+func TestKickInTheLobbyIsNotSnapshotted(t *testing.T) {
+	dir, err := ioutil.TempDir("", "godr4ft")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	var options game.GeneralOptions
+	d := director.NewGameDirector(options, 9000, "lobby_kick_game")
+	host, _ := director.NewClient(d)
+	player, _ := director.NewClient(d)
+	d.Clients[host.Id] = host
+	d.Clients[player.Id] = player
+	path := filepath.Join(dir, "snapshot.json")
+	d.SetSnapshotPath(path, time.Millisecond)
+
+	d.KickPlayer(player.Id)
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("expected no snapshot of a lobby, it would restore as a started game")
+	}
+}
@@ -0,0 +1,309 @@
+package director
+
+import (
+	"errors"
+	"fmt"
+	"github.com/malexanderboyd/pwr9-godr4ft/internal/director/models"
+	"math/rand"
+	"strings"
+)
+
+// SheetFilter picks the cards of a set that go on a print sheet. A card is on
+// the sheet when it matches one of every non-empty list and none of the
+// exclusions; back faces are never on a sheet.
+type SheetFilter struct {
+	Rarities          []string `json:"rarities"`
+	Layouts           []string `json:"layouts"`
+	Supertypes        []string `json:"supertypes"`
+	Subtypes          []string `json:"subtypes"`
+	ExcludeLayouts    []string `json:"excludeLayouts"`
+	ExcludeSupertypes []string `json:"excludeSupertypes"`
+	ExcludeSubtypes   []string `json:"excludeSubtypes"`
+}
+
+// SlotOption is one sheet a slot can be filled from, chosen by Weight
+// against the slot's other options.
+type SlotOption struct {
+	Sheet  string `json:"sheet"`
+	Weight int    `json:"weight"`
+	Foil   bool   `json:"foil"`
+}
+
+type BoosterSlot struct {
+	Name    string       `json:"name"`
+	Count   int          `json:"count"`
+	Options []SlotOption `json:"options"`
+}
+
+// BoosterTemplate is a set's collation, the named sheets its slots draw from
+// and the slots in the order they are opened.
+type BoosterTemplate struct {
+	Sheets map[string]SheetFilter `json:"sheets"`
+	Slots  []BoosterSlot          `json:"slots"`
+}
+
+var nonBasic = []string{"Basic"}
+var doubleFacedLayouts = []string{"transform", "modal_dfc", "meld"}
+
+var defaultSheets = map[string]SheetFilter{
+	"common":   {Rarities: []string{"common"}, ExcludeSupertypes: nonBasic},
+	"uncommon": {Rarities: []string{"uncommon"}, ExcludeSupertypes: nonBasic},
+	"rare":     {Rarities: []string{"rare"}, ExcludeSupertypes: nonBasic},
+	"mythic":   {Rarities: []string{"mythic"}, ExcludeSupertypes: nonBasic},
+	"foil":     {ExcludeSupertypes: nonBasic},
+	"basic":    {Supertypes: nonBasic},
+}
+
+var (
+	rareSlot = BoosterSlot{Name: "rare", Count: 1, Options: []SlotOption{
+		{Sheet: "rare", Weight: 7},
+		{Sheet: "mythic", Weight: 1},
+	}}
+	uncommonSlot = BoosterSlot{Name: "uncommon", Count: 3, Options: []SlotOption{{Sheet: "uncommon", Weight: 1}}}
+	// a foil of any rarity replaces a common in about one pack in six
+	foilSlot = BoosterSlot{Name: "foil", Count: 1, Options: []SlotOption{
+		{Sheet: "common", Weight: 5},
+		{Sheet: "foil", Weight: 1, Foil: true},
+	}}
+	landSlot = BoosterSlot{Name: "land", Count: 1, Options: []SlotOption{{Sheet: "basic", Weight: 1}}}
+)
+
+// DefaultBoosterTemplate is a 15 card draft booster: a rare or 1 in 8
+// mythic, 3 uncommons, 9 commons, a common or foil and a basic land.
+var DefaultBoosterTemplate = BoosterTemplate{
+	Sheets: defaultSheets,
+	Slots: []BoosterSlot{
+		rareSlot,
+		uncommonSlot,
+		{Name: "common", Count: 9, Options: []SlotOption{{Sheet: "common", Weight: 1}}},
+		foilSlot,
+		landSlot,
+	},
+}
+
+// GuildgateBoosterTemplate swaps the basic land for a guildgate, ie: GRN, RNA
+var GuildgateBoosterTemplate = BoosterTemplate{
+	Sheets: withSheets(defaultSheets, map[string]SheetFilter{
+		"common": {Rarities: []string{"common"}, ExcludeSupertypes: nonBasic, ExcludeSubtypes: []string{"Gate"}},
+		"gate":   {Rarities: []string{"common"}, Subtypes: []string{"Gate"}},
+	}),
+	Slots: []BoosterSlot{
+		rareSlot,
+		uncommonSlot,
+		{Name: "common", Count: 9, Options: []SlotOption{{Sheet: "common", Weight: 1}}},
+		foilSlot,
+		{Name: "land", Count: 1, Options: []SlotOption{{Sheet: "gate", Weight: 1}}},
+	},
+}
+
+// DoubleFacedBoosterTemplate gives double faced cards their own slot and
+// keeps them off the regular sheets, ie: ISD, SOI, MID
+var DoubleFacedBoosterTemplate = BoosterTemplate{
+	Sheets: withSheets(defaultSheets, map[string]SheetFilter{
+		"common":       {Rarities: []string{"common"}, ExcludeSupertypes: nonBasic, ExcludeLayouts: doubleFacedLayouts},
+		"uncommon":     {Rarities: []string{"uncommon"}, ExcludeLayouts: doubleFacedLayouts},
+		"rare":         {Rarities: []string{"rare"}, ExcludeLayouts: doubleFacedLayouts},
+		"mythic":       {Rarities: []string{"mythic"}, ExcludeLayouts: doubleFacedLayouts},
+		"dfc_common":   {Rarities: []string{"common"}, Layouts: doubleFacedLayouts},
+		"dfc_uncommon": {Rarities: []string{"uncommon"}, Layouts: doubleFacedLayouts},
+		"dfc_rare":     {Rarities: []string{"rare", "mythic"}, Layouts: doubleFacedLayouts},
+	}),
+	Slots: []BoosterSlot{
+		rareSlot,
+		uncommonSlot,
+		{Name: "common", Count: 8, Options: []SlotOption{{Sheet: "common", Weight: 1}}},
+		foilSlot,
+		{Name: "dfc", Count: 1, Options: []SlotOption{
+			{Sheet: "dfc_common", Weight: 10},
+			{Sheet: "dfc_uncommon", Weight: 3},
+			{Sheet: "dfc_rare", Weight: 1},
+		}},
+		landSlot,
+	},
+}
+
+// boosterTemplates holds the sets that don't use DefaultBoosterTemplate
+var boosterTemplates = map[string]BoosterTemplate{
+	"GRN": GuildgateBoosterTemplate,
+	"RNA": GuildgateBoosterTemplate,
+	"ISD": DoubleFacedBoosterTemplate,
+	"DKA": DoubleFacedBoosterTemplate,
+	"SOI": DoubleFacedBoosterTemplate,
+	"EMN": DoubleFacedBoosterTemplate,
+	"MID": DoubleFacedBoosterTemplate,
+	"VOW": DoubleFacedBoosterTemplate,
+}
+
+func withSheets(sheets map[string]SheetFilter, overrides map[string]SheetFilter) map[string]SheetFilter {
+	merged := make(map[string]SheetFilter)
+	for name, filter := range sheets {
+		merged[name] = filter
+	}
+	for name, filter := range overrides {
+		merged[name] = filter
+	}
+	return merged
+}
+
+func GetBoosterTemplate(setAbbrev string) BoosterTemplate {
+	if template, ok := boosterTemplates[strings.ToUpper(setAbbrev)]; ok {
+		return template
+	}
+	return DefaultBoosterTemplate
+}
+
+func containsAny(values []string, wanted []string) bool {
+	for _, value := range values {
+		for _, w := range wanted {
+			if strings.EqualFold(value, w) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func getSupertypes(card models.SetCard) []string {
+	var supertypes []string
+	for _, supertype := range card.Supertypes {
+		if s, ok := supertype.(string); ok {
+			supertypes = append(supertypes, s)
+		}
+	}
+	return supertypes
+}
+
+func (filter SheetFilter) matches(card models.SetCard) bool {
+	if card.Side != "" && card.Side != "a" {
+		return false
+	}
+	supertypes := getSupertypes(card)
+	if len(filter.Rarities) > 0 && !containsAny([]string{card.Rarity}, filter.Rarities) {
+		return false
+	}
+	if len(filter.Layouts) > 0 && !containsAny([]string{card.Layout}, filter.Layouts) {
+		return false
+	}
+	if len(filter.Supertypes) > 0 && !containsAny(supertypes, filter.Supertypes) {
+		return false
+	}
+	if len(filter.Subtypes) > 0 && !containsAny(card.Subtypes, filter.Subtypes) {
+		return false
+	}
+	if containsAny([]string{card.Layout}, filter.ExcludeLayouts) || containsAny(supertypes, filter.ExcludeSupertypes) ||
+		containsAny(card.Subtypes, filter.ExcludeSubtypes) {
+		return false
+	}
+	return true
+}
+
+// BoosterGenerator opens boosters of one set following a template
+type BoosterGenerator struct {
+	setAbbrev string
+	template  BoosterTemplate
+	sheets    map[string][]models.SetCard
+	rng       *rand.Rand
+}
+
+func NewBoosterGenerator(setAbbrev string, cards []models.SetCard, template BoosterTemplate, rng *rand.Rand) (*BoosterGenerator, error) {
+	sheets := make(map[string][]models.SetCard)
+	for name, filter := range template.Sheets {
+		for _, card := range cards {
+			if filter.matches(card) {
+				sheets[name] = append(sheets[name], card)
+			}
+		}
+	}
+
+	for _, slot := range template.Slots {
+		if slot.Count < 1 {
+			return nil, errors.New(fmt.Sprintf("%s booster slot %s has count %d", setAbbrev, slot.Name, slot.Count))
+		}
+		for _, option := range slot.Options {
+			if _, ok := template.Sheets[option.Sheet]; !ok {
+				return nil, errors.New(fmt.Sprintf("%s booster slot %s uses unknown sheet %s", setAbbrev, slot.Name, option.Sheet))
+			}
+			if option.Weight < 1 {
+				return nil, errors.New(fmt.Sprintf("%s booster slot %s has sheet %s with weight %d", setAbbrev, slot.Name, option.Sheet, option.Weight))
+			}
+		}
+	}
+
+	generator := &BoosterGenerator{
+		setAbbrev: setAbbrev,
+		template:  template,
+		sheets:    sheets,
+		rng:       rng,
+	}
+	if len(generator.Open()) == 0 {
+		return nil, errors.New(fmt.Sprintf("set %s has no cards for any booster slot", setAbbrev))
+	}
+	return generator, nil
+}
+
+// chooseOption picks one of a slot's options by weight, skipping sheets
+// with no cards so a set without mythics always opens a rare.
+func (generator *BoosterGenerator) chooseOption(slot BoosterSlot) (SlotOption, bool) {
+	total := 0
+	for _, option := range slot.Options {
+		if len(generator.sheets[option.Sheet]) > 0 {
+			total += option.Weight
+		}
+	}
+	if total == 0 {
+		return SlotOption{}, false
+	}
+
+	roll := generator.rng.Intn(total)
+	for _, option := range slot.Options {
+		if len(generator.sheets[option.Sheet]) == 0 {
+			continue
+		}
+		if roll < option.Weight {
+			return option, true
+		}
+		roll -= option.Weight
+	}
+	return SlotOption{}, false
+}
+
+// drawCard takes a random card off a sheet, avoiding cards already in the
+// pack until the sheet runs out.
+func (generator *BoosterGenerator) drawCard(sheet []models.SetCard, opened map[string]bool) models.SetCard {
+	for _, i := range generator.rng.Perm(len(sheet)) {
+		if !opened[sheet[i].UUID] {
+			return sheet[i]
+		}
+	}
+	return sheet[generator.rng.Intn(len(sheet))]
+}
+
+// Open generates one booster, slots without any cards in the set are left out
+func (generator *BoosterGenerator) Open() []models.SetCard {
+	var pack []models.SetCard
+	opened := make(map[string]bool)
+	for _, slot := range generator.template.Slots {
+		for i := 0; i < slot.Count; i++ {
+			option, ok := generator.chooseOption(slot)
+			if !ok {
+				break
+			}
+			card := generator.drawCard(generator.sheets[option.Sheet], opened)
+			opened[card.UUID] = true
+			card.Foil = option.Foil
+			if card.SetCode == "" {
+				card.SetCode = generator.setAbbrev
+			}
+			pack = append(pack, card)
+		}
+	}
+	return pack
+}
+
+func (generator *BoosterGenerator) OpenBoosters(n int) models.SetPacks {
+	var boosters models.SetPacks
+	for i := 0; i < n; i++ {
+		boosters.Packs = append(boosters.Packs, generator.Open())
+	}
+	return boosters
+}
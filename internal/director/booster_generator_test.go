@@ -0,0 +1,150 @@
+package director_test
+
+import (
+	"fmt"
+	"github.com/malexanderboyd/pwr9-godr4ft/internal/director"
+	"github.com/malexanderboyd/pwr9-godr4ft/internal/director/models"
+	"math/rand"
+	"testing"
+)
+
+func getTestSetCards() []models.SetCard {
+	var cards []models.SetCard
+	add := func(n int, rarity, layout string, subtypes []string, supertypes []interface{}) {
+		for i := 0; i < n; i++ {
+			cards = append(cards, models.SetCard{
+				Name:       fmt.Sprintf("%s %s %d", layout, rarity, len(cards)),
+				UUID:       fmt.Sprintf("%s-%s-%d", layout, rarity, len(cards)),
+				Rarity:     rarity,
+				Layout:     layout,
+				Subtypes:   subtypes,
+				Supertypes: supertypes,
+			})
+		}
+	}
+	add(20, "common", "normal", nil, nil)
+	add(6, "uncommon", "normal", nil, nil)
+	add(4, "rare", "normal", nil, nil)
+	add(2, "mythic", "normal", nil, nil)
+	add(3, "common", "transform", nil, nil)
+	add(2, "uncommon", "transform", nil, nil)
+	add(1, "rare", "transform", nil, nil)
+	add(2, "common", "normal", []string{"Gate"}, nil)
+	add(5, "common", "normal", nil, []interface{}{"Basic"})
+	return cards
+}
+
+func TestBoosterGeneratorDefaultTemplate(t *testing.T) {
+	generator, err := director.NewBoosterGenerator("TST", getTestSetCards(), director.DefaultBoosterTemplate, rand.New(rand.NewSource(1)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mythics, foils := 0, 0
+	total := 4000
+	for _, pack := range generator.OpenBoosters(total).Packs {
+		if len(pack) != 15 {
+			t.Fatalf("expected 15 card boosters, got %d", len(pack))
+		}
+		if pack[0].Rarity == "mythic" {
+			mythics++
+		} else if pack[0].Rarity != "rare" {
+			t.Errorf("expected a rare or mythic first, got %s", pack[0].Rarity)
+		}
+		for i := 1; i <= 3; i++ {
+			if pack[i].Rarity != "uncommon" {
+				t.Errorf("expected uncommons in slots 2-4, got %s", pack[i].Rarity)
+			}
+		}
+		if pack[13].Foil {
+			foils++
+		}
+		if pack[14].Supertypes == nil {
+			t.Errorf("expected a basic land last, got %s", pack[14].Name)
+		}
+		seen := make(map[string]bool)
+		for _, card := range pack {
+			if seen[card.UUID] && !card.Foil {
+				t.Errorf("%s opened twice in one booster", card.Name)
+			}
+			seen[card.UUID] = true
+			if card.SetCode != "TST" {
+				t.Errorf("expected cards stamped with TST, got %q", card.SetCode)
+			}
+		}
+	}
+
+	// 1 in 8 mythics and 1 in 6 foils, with plenty of room for chance
+	if mythics < total/12 || mythics > total/6 {
+		t.Errorf("expected about %d mythics, got %d", total/8, mythics)
+	}
+	if foils < total/9 || foils > total/4 {
+		t.Errorf("expected about %d foils, got %d", total/6, foils)
+	}
+}
+
+func TestBoosterGeneratorSetSheets(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	dfc, err := director.NewBoosterGenerator("ISD", getTestSetCards(), director.GetBoosterTemplate("isd"), rng)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, pack := range dfc.OpenBoosters(200).Packs {
+		dfcs := 0
+		for i, card := range pack {
+			if card.Layout == "transform" && !card.Foil {
+				dfcs++
+				if i != 13 {
+					t.Errorf("expected double faced cards only in the dfc slot, got one at %d", i)
+				}
+			}
+		}
+		if dfcs != 1 {
+			t.Errorf("expected one double faced card per booster, got %d", dfcs)
+		}
+	}
+
+	gates, err := director.NewBoosterGenerator("GRN", getTestSetCards(), director.GetBoosterTemplate("GRN"), rng)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, pack := range gates.OpenBoosters(200).Packs {
+		for i, card := range pack {
+			isGate := len(card.Subtypes) > 0 && card.Subtypes[0] == "Gate"
+			if (i == len(pack)-1) != isGate && !card.Foil {
+				t.Errorf("expected a guildgate in the land slot only, got %s at %d", card.Name, i)
+			}
+		}
+	}
+}
+
+func TestBoosterGeneratorTemplateErrors(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	unknownSheet := director.BoosterTemplate{
+		Sheets: map[string]director.SheetFilter{"common": {Rarities: []string{"common"}}},
+		Slots:  []director.BoosterSlot{{Name: "rare", Count: 1, Options: []director.SlotOption{{Sheet: "rare", Weight: 1}}}},
+	}
+	if _, err := director.NewBoosterGenerator("TST", getTestSetCards(), unknownSheet, rng); err == nil {
+		t.Errorf("expected an error for a slot using an unknown sheet")
+	}
+	if _, err := director.NewBoosterGenerator("TST", nil, director.DefaultBoosterTemplate, rng); err == nil {
+		t.Errorf("expected an error for a set with no cards")
+	}
+
+	// a set without mythics always opens a rare
+	var noMythics []models.SetCard
+	for _, card := range getTestSetCards() {
+		if card.Rarity != "mythic" {
+			noMythics = append(noMythics, card)
+		}
+	}
+	generator, err := director.NewBoosterGenerator("TST", noMythics, director.DefaultBoosterTemplate, rng)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, pack := range generator.OpenBoosters(100).Packs {
+		if pack[0].Rarity != "rare" {
+			t.Fatalf("expected a rare, got %s", pack[0].Rarity)
+		}
+	}
+}
@@ -0,0 +1,39 @@
+package director
+
+import (
+	"github.com/malexanderboyd/pwr9-godr4ft/internal/director/models"
+	"strconv"
+)
+
+// fetchRegularRounds opens a booster of each selected set for every seat,
+// selectedPacks is keyed by pack number starting at "0"
+func fetchRegularRounds(source CardSource, selectedPacks map[string]string, totalPacks, totalSeats int) (map[int]models.DraftRound, error) {
+	rounds := make(map[int]models.DraftRound)
+	for i := 0; i < totalPacks; i++ {
+		setAbbrev := selectedPacks[strconv.Itoa(i)]
+		boosters, err := source.GetBoosters(setAbbrev, totalSeats)
+		if err != nil {
+			return nil, err
+		}
+
+		playerPacks := make(map[int][]models.SetCard)
+		for seat, pack := range boosters.Packs {
+			playerPacks[seat] = stampSetCode(pack, setAbbrev)
+		}
+		rounds[i] = models.DraftRound{
+			SetAbbreviation: setAbbrev,
+			PlayerPacks:     playerPacks,
+		}
+	}
+	return rounds, nil
+}
+
+// stampSetCode records which set every card in pack was opened from
+func stampSetCode(pack []models.SetCard, setAbbrev string) []models.SetCard {
+	for i := range pack {
+		if pack[i].SetCode == "" {
+			pack[i].SetCode = setAbbrev
+		}
+	}
+	return pack
+}
@@ -0,0 +1,171 @@
+package director
+
+import (
+	"errors"
+	"fmt"
+	"github.com/malexanderboyd/pwr9-godr4ft/internal/director/models"
+	"math/rand"
+	"sort"
+)
+
+const (
+	RarityFirstBotStrategy    = "rarity"
+	ColorCommittedBotStrategy = "color"
+	RandomBotStrategy         = "random"
+
+	DefaultBotStrategy = RarityFirstBotStrategy
+	// picks a color committed bot takes on rarity alone before settling into colors
+	DefaultColorCommitPicks = 5
+)
+
+var rarityRanks = map[string]int{
+	"mythic":   4,
+	"rare":     3,
+	"uncommon": 2,
+	"common":   1,
+}
+
+// BotStrategy chooses which card a bot takes, it returns an index into pack.
+type BotStrategy interface {
+	PickCard(pack []models.SetCard, pool []models.SetCard) int
+}
+
+func NewBotStrategy(name string, rng *rand.Rand) (BotStrategy, error) {
+	switch name {
+	case RarityFirstBotStrategy, "":
+		return &RarityFirstStrategy{}, nil
+	case ColorCommittedBotStrategy:
+		return &ColorCommittedStrategy{CommitAfter: DefaultColorCommitPicks}, nil
+	case RandomBotStrategy:
+		return &RandomStrategy{rng: rng}, nil
+	default:
+		return nil, errors.New(fmt.Sprintf("unknown bot strategy: %s", name))
+	}
+}
+
+// RarityFirstStrategy takes the rarest card, breaking ties on EDHREC rank.
+type RarityFirstStrategy struct{}
+
+func (s *RarityFirstStrategy) PickCard(pack []models.SetCard, pool []models.SetCard) int {
+	return bestCardIndex(pack, func(card models.SetCard) bool {
+		return true
+	})
+}
+
+// ColorCommittedStrategy drafts like RarityFirstStrategy for its first
+// CommitAfter picks, then sticks to the two colors it has the most cards in.
+type ColorCommittedStrategy struct {
+	CommitAfter int
+}
+
+func (s *ColorCommittedStrategy) PickCard(pack []models.SetCard, pool []models.SetCard) int {
+	if len(pool) < s.CommitAfter {
+		return bestCardIndex(pack, func(card models.SetCard) bool {
+			return true
+		})
+	}
+
+	colors := getTopColors(pool, 2)
+	onColor := bestCardIndex(pack, func(card models.SetCard) bool {
+		for _, color := range card.Colors {
+			if !colors[color] {
+				return false
+			}
+		}
+		return true
+	})
+	if onColor >= 0 {
+		return onColor
+	}
+	return bestCardIndex(pack, func(card models.SetCard) bool {
+		return true
+	})
+}
+
+// RandomStrategy takes any card.
+type RandomStrategy struct {
+	rng *rand.Rand
+}
+
+func (s *RandomStrategy) PickCard(pack []models.SetCard, pool []models.SetCard) int {
+	if len(pack) == 0 {
+		return -1
+	}
+	return s.rng.Intn(len(pack))
+}
+
+// bestCardIndex returns the index of the rarest card in pack that passes
+// allowed, or -1 if none do.
+func bestCardIndex(pack []models.SetCard, allowed func(card models.SetCard) bool) int {
+	best := -1
+	for i, card := range pack {
+		if !allowed(card) {
+			continue
+		}
+		if best < 0 || isBetterCard(card, pack[best]) {
+			best = i
+		}
+	}
+	return best
+}
+
+func isBetterCard(card, other models.SetCard) bool {
+	if rarityRanks[card.Rarity] != rarityRanks[other.Rarity] {
+		return rarityRanks[card.Rarity] > rarityRanks[other.Rarity]
+	}
+	// unranked cards sort last
+	if card.EdhrecRank == 0 || other.EdhrecRank == 0 {
+		return card.EdhrecRank != 0
+	}
+	return card.EdhrecRank < other.EdhrecRank
+}
+
+func getTopColors(pool []models.SetCard, n int) map[string]bool {
+	counts := make(map[string]int)
+	for _, card := range pool {
+		for _, color := range card.Colors {
+			counts[color]++
+		}
+	}
+
+	var colors []string
+	for color := range counts {
+		colors = append(colors, color)
+	}
+	sort.Slice(colors, func(i, j int) bool {
+		if counts[colors[i]] != counts[colors[j]] {
+			return counts[colors[i]] > counts[colors[j]]
+		}
+		return colors[i] < colors[j]
+	})
+
+	top := make(map[string]bool)
+	for i := 0; i < n && i < len(colors); i++ {
+		top[colors[i]] = true
+	}
+	return top
+}
+
+// Bot drafts from a seat no player took.
+type Bot struct {
+	Id       string
+	strategy BotStrategy
+	pool     []models.SetCard
+}
+
+func NewBot(director *GameDirector, seat int, strategy BotStrategy) *Bot {
+	return &Bot{
+		Id:       fmt.Sprintf("%s_bot_%d", director.GameId, seat),
+		strategy: strategy,
+	}
+}
+
+func (b *Bot) AddCardToPool(card models.SetCard) {
+	b.pool = append(b.pool, card)
+}
+
+func (b *Bot) WriteCurrentPool() {}
+
+func (b *Bot) PickCard(pack []models.SetCard) int {
+	return b.strategy.PickCard(pack, b.pool)
+}
@@ -0,0 +1,77 @@
+package director_test
+
+import (
+	"github.com/malexanderboyd/pwr9-godr4ft/internal/director"
+	"github.com/malexanderboyd/pwr9-godr4ft/internal/director/models"
+	"math/rand"
+	"testing"
+)
+
+func TestRarityFirstStrategy(t *testing.T) {
+	pack := []models.SetCard{
+		{Name: "Shock", Rarity: "common"},
+		{Name: "Llanowar Elves", Rarity: "common", EdhrecRank: 10},
+		{Name: "Murder", Rarity: "uncommon", EdhrecRank: 500},
+		{Name: "Murderous Rider", Rarity: "rare", EdhrecRank: 300},
+		{Name: "Opt", Rarity: "common", EdhrecRank: 5},
+	}
+
+	strategy := &director.RarityFirstStrategy{}
+	if pick := strategy.PickCard(pack, nil); pack[pick].Name != "Murderous Rider" {
+		t.Errorf("expected the rare, picked %s", pack[pick].Name)
+	}
+
+	commons := pack[:2]
+	if pick := strategy.PickCard(commons, nil); commons[pick].Name != "Llanowar Elves" {
+		t.Errorf("expected the ranked common, picked %s", commons[pick].Name)
+	}
+}
+
+func TestColorCommittedStrategy(t *testing.T) {
+	pool := []models.SetCard{
+		{Name: "Shock", Colors: []string{"R"}},
+		{Name: "Lightning Strike", Colors: []string{"R"}},
+		{Name: "Opt", Colors: []string{"U"}},
+		{Name: "Essence Scatter", Colors: []string{"U"}},
+		{Name: "Pacifism", Colors: []string{"W"}},
+	}
+	pack := []models.SetCard{
+		{Name: "Ajani's Pridemate", Rarity: "uncommon", Colors: []string{"W"}},
+		{Name: "Frilled Mystic", Rarity: "uncommon", Colors: []string{"G", "U"}},
+		{Name: "Dragon's Hoard", Rarity: "rare", Colors: []string{}},
+		{Name: "Dark Bargain", Rarity: "mythic", Colors: []string{"B"}},
+		{Name: "Izzet Charm", Rarity: "common", Colors: []string{"R", "U"}},
+	}
+
+	strategy := &director.ColorCommittedStrategy{CommitAfter: 5}
+	if pick := strategy.PickCard(pack, pool[:4]); pack[pick].Name != "Dark Bargain" {
+		t.Errorf("expected the mythic before committing, picked %s", pack[pick].Name)
+	}
+
+	if pick := strategy.PickCard(pack, pool); pack[pick].Name != "Dragon's Hoard" {
+		t.Errorf("expected the best colorless or U/R card once committed, picked %s", pack[pick].Name)
+	}
+
+	offColor := []models.SetCard{pack[0], pack[3]}
+	if pick := strategy.PickCard(offColor, pool); offColor[pick].Name != "Dark Bargain" {
+		t.Errorf("expected the rarest card when nothing is on color, picked %s", offColor[pick].Name)
+	}
+}
+
+func TestNewBotStrategy(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for _, name := range []string{"", director.RarityFirstBotStrategy, director.ColorCommittedBotStrategy, director.RandomBotStrategy} {
+		strategy, err := director.NewBotStrategy(name, rng)
+		if err != nil {
+			t.Fatalf("strategy %q: unexpected error %v", name, err)
+		}
+		pack := []models.SetCard{{Name: "Shock"}, {Name: "Opt"}}
+		if pick := strategy.PickCard(pack, nil); pick < 0 || pick >= len(pack) {
+			t.Errorf("strategy %q picked out of range index %d", name, pick)
+		}
+	}
+
+	if _, err := director.NewBotStrategy("telepathic", rng); err == nil {
+		t.Errorf("expected an error for an unknown strategy")
+	}
+}
@@ -0,0 +1,114 @@
+package director
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/malexanderboyd/pwr9-godr4ft/internal"
+	"github.com/malexanderboyd/pwr9-godr4ft/internal/director/models"
+	"github.com/malexanderboyd/pwr9-godr4ft/internal/game"
+	"io/ioutil"
+	"net/http"
+	"time"
+)
+
+// CardSource provides a game's options and the card data to build its packs
+type CardSource interface {
+	GetGameOptions(gameId string) (game.GeneralOptions, error)
+	GetSets() ([]models.SetInfo, error)
+	// GetBoosters opens n boosters of a set
+	GetBoosters(setAbbrev string, n int) (models.SetPacks, error)
+}
+
+// HTTPCardSource fetches everything from the companion API service
+type HTTPCardSource struct {
+	Uri string
+	// opens boosters of sets the API can't, ie: an MTGJSONCardSource generating
+	// them from set files. Nil leaves those drafts failing.
+	Fallback CardSource
+}
+
+func NewHTTPCardSource(uri string) *HTTPCardSource {
+	return &HTTPCardSource{Uri: uri}
+}
+
+func (source *HTTPCardSource) GetGameOptions(gameId string) (game.GeneralOptions, error) {
+	var gameOptions game.GeneralOptions
+	defer observeUpstreamFetch("game_options", time.Now())
+	res, err := http.Get(fmt.Sprintf("%s/game/%s", source.Uri, gameId))
+	if err != nil {
+		return gameOptions, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return gameOptions, errors.New(fmt.Sprintf("cannot get options for game %s: %s", gameId, res.Status))
+	}
+	if err := json.NewDecoder(res.Body).Decode(&gameOptions); err != nil {
+		return gameOptions, err
+	}
+	return gameOptions, nil
+}
+
+func (source *HTTPCardSource) GetSets() ([]models.SetInfo, error) {
+	var sets []models.SetInfo
+	defer observeUpstreamFetch("sets", time.Now())
+	res, err := http.Get(fmt.Sprintf("%s/sets", source.Uri))
+	if err != nil {
+		return sets, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return sets, errors.New(fmt.Sprintf("cannot get set list: %s", res.Status))
+	}
+	if err := json.NewDecoder(res.Body).Decode(&sets); err != nil {
+		return sets, errors.New(fmt.Sprintf("cannot decode set list json: %s", err.Error()))
+	}
+	return sets, nil
+}
+
+// GetBoosters opens boosters through the API, sets it has no boosters for are
+// opened by the Fallback source instead
+func (source *HTTPCardSource) GetBoosters(setAbbrev string, n int) (models.SetPacks, error) {
+	boosters, err := source.fetchBoosters(setAbbrev, n)
+	if err != nil && source.Fallback != nil {
+		internal.GetLogger().Infow("Generating boosters the API cannot open", "set", setAbbrev, "error", err.Error())
+		return source.Fallback.GetBoosters(setAbbrev, n)
+	}
+	return boosters, err
+}
+
+func (source *HTTPCardSource) fetchBoosters(setAbbrev string, n int) (models.SetPacks, error) {
+	var boosters models.SetPacks
+	defer observeUpstreamFetch("boosters", time.Now())
+	res, err := http.Get(fmt.Sprintf("%s/set/%s/pack?n=%d", source.Uri, setAbbrev, n))
+	if err != nil {
+		return boosters, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return boosters, errors.New(fmt.Sprintf("cannot get %s boosters: %s", setAbbrev, res.Status))
+	}
+	msg, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return boosters, err
+	}
+
+	if err := json.Unmarshal(msg, &boosters); err != nil {
+		return boosters, errors.New(fmt.Sprintf("cannot decode booster json for set %s: %s", setAbbrev, err.Error()))
+	}
+
+	if len(boosters.Packs) < n {
+		return boosters, errors.New(fmt.Sprintf("asked for %d %s boosters, got %d", n, setAbbrev, len(boosters.Packs)))
+	}
+	return boosters, nil
+}
+
+func (director *GameDirector) getCardSource() CardSource {
+	if director.cardSource != nil {
+		return director.cardSource
+	}
+	return NewHTTPCardSource(ApiUri)
+}
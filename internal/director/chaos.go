@@ -0,0 +1,159 @@
+package director
+
+import (
+	"errors"
+	"fmt"
+	"github.com/malexanderboyd/pwr9-godr4ft/internal/director/models"
+	"math/rand"
+)
+
+const ChaosSetAbbreviation = "CHAOS"
+
+// first day of the modern card frame, 8th Edition
+const modernFirstReleaseDate = "2003-07-28"
+
+// set types that have draft boosters
+var draftableSetTypes = map[string]bool{
+	"core":             true,
+	"expansion":        true,
+	"masters":          true,
+	"draft_innovation": true,
+}
+
+func getChaosSets(sets []models.SetInfo, onlyModern bool) []models.SetInfo {
+	var chaosSets []models.SetInfo
+	for _, set := range sets {
+		if !draftableSetTypes[set.Type] {
+			continue
+		}
+		// release dates are ISO 8601 so they compare as strings
+		if onlyModern && set.ReleaseDate < modernFirstReleaseDate {
+			continue
+		}
+		chaosSets = append(chaosSets, set)
+	}
+	return chaosSets
+}
+
+// buildChaosRounds opens a booster from a random set for every seat and pack
+// round. With totalChaos every card is drawn from a random set instead, see
+// buildTotalChaosRounds.
+func buildChaosRounds(source CardSource, sets []models.SetInfo, totalSeats, totalPacks int, totalChaos bool, rng *rand.Rand) (map[int]models.DraftRound, error) {
+	if len(sets) == 0 {
+		return nil, errors.New("no sets available for a chaos draft")
+	}
+	if totalSeats < 1 || totalPacks < 1 {
+		return nil, errors.New(fmt.Sprintf("invalid chaos settings seats=%d packs=%d", totalSeats, totalPacks))
+	}
+	if totalChaos {
+		return buildTotalChaosRounds(source, sets, totalSeats, totalPacks, rng)
+	}
+
+	// pick every booster's set up front so each set is only fetched once
+	packSets := make([][]string, totalPacks)
+	setCounts := make(map[string]int)
+	for packNumber := 0; packNumber < totalPacks; packNumber++ {
+		packSets[packNumber] = make([]string, totalSeats)
+		for seat := 0; seat < totalSeats; seat++ {
+			setAbbrev := sets[rng.Intn(len(sets))].Code
+			packSets[packNumber][seat] = setAbbrev
+			setCounts[setAbbrev]++
+		}
+	}
+
+	boostersBySet := make(map[string][][]models.SetCard)
+	for setAbbrev, n := range setCounts {
+		boosters, err := source.GetBoosters(setAbbrev, n)
+		if err != nil {
+			return nil, err
+		}
+		boostersBySet[setAbbrev] = boosters.Packs
+	}
+
+	rounds := make(map[int]models.DraftRound)
+	for packNumber := 0; packNumber < totalPacks; packNumber++ {
+		playerPacks := make(map[int][]models.SetCard)
+		packSetNames := make(map[int]string)
+		for seat := 0; seat < totalSeats; seat++ {
+			setAbbrev := packSets[packNumber][seat]
+			playerPacks[seat] = stampSetCode(boostersBySet[setAbbrev][0], setAbbrev)
+			boostersBySet[setAbbrev] = boostersBySet[setAbbrev][1:]
+			packSetNames[seat] = setAbbrev
+		}
+		rounds[packNumber] = models.DraftRound{
+			SetAbbreviation: ChaosSetAbbreviation,
+			PlayerPacks:     playerPacks,
+			PackSetNames:    packSetNames,
+		}
+	}
+	return rounds, nil
+}
+
+// buildTotalChaosRounds draws every card of every pack from its own random set
+// out of the whole chaos pool. Packs are as big as the first booster opened,
+// each set is opened only as often as the cards drawn from it need.
+func buildTotalChaosRounds(source CardSource, sets []models.SetInfo, totalSeats, totalPacks int, rng *rand.Rand) (map[int]models.DraftRound, error) {
+	firstSet := sets[rng.Intn(len(sets))].Code
+	first, err := source.GetBoosters(firstSet, 1)
+	if err != nil {
+		return nil, err
+	}
+	if len(first.Packs) == 0 || len(first.Packs[0]) == 0 {
+		return nil, errors.New(fmt.Sprintf("opened an empty %s booster", firstSet))
+	}
+	packSize := len(first.Packs[0])
+
+	slotSets := make([]string, totalSeats*totalPacks*packSize)
+	needed := make(map[string]int)
+	for i := range slotSets {
+		slotSets[i] = sets[rng.Intn(len(sets))].Code
+		needed[slotSets[i]]++
+	}
+
+	cardsBySet := map[string][]models.SetCard{firstSet: stampSetCode(first.Packs[0], firstSet)}
+	for setAbbrev, n := range needed {
+		for len(cardsBySet[setAbbrev]) < n {
+			missing := n - len(cardsBySet[setAbbrev])
+			boosters, err := source.GetBoosters(setAbbrev, (missing+packSize-1)/packSize)
+			if err != nil {
+				return nil, err
+			}
+			opened := 0
+			for _, pack := range boosters.Packs {
+				cardsBySet[setAbbrev] = append(cardsBySet[setAbbrev], stampSetCode(pack, setAbbrev)...)
+				opened += len(pack)
+			}
+			if opened == 0 {
+				return nil, errors.New(fmt.Sprintf("opened only empty %s boosters", setAbbrev))
+			}
+		}
+		cards := cardsBySet[setAbbrev]
+		rng.Shuffle(len(cards), func(i, j int) {
+			cards[i], cards[j] = cards[j], cards[i]
+		})
+	}
+
+	rounds := make(map[int]models.DraftRound)
+	next := 0
+	for packNumber := 0; packNumber < totalPacks; packNumber++ {
+		playerPacks := make(map[int][]models.SetCard)
+		packSetNames := make(map[int]string)
+		for seat := 0; seat < totalSeats; seat++ {
+			pack := make([]models.SetCard, 0, packSize)
+			for i := 0; i < packSize; i++ {
+				setAbbrev := slotSets[next]
+				pack = append(pack, cardsBySet[setAbbrev][0])
+				cardsBySet[setAbbrev] = cardsBySet[setAbbrev][1:]
+				next++
+			}
+			playerPacks[seat] = pack
+			packSetNames[seat] = ChaosSetAbbreviation
+		}
+		rounds[packNumber] = models.DraftRound{
+			SetAbbreviation: ChaosSetAbbreviation,
+			PlayerPacks:     playerPacks,
+			PackSetNames:    packSetNames,
+		}
+	}
+	return rounds, nil
+}
@@ -0,0 +1,204 @@
+package director
+
+import (
+	"errors"
+	"fmt"
+	"github.com/malexanderboyd/pwr9-godr4ft/internal/director/models"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	MaxChatMessageLength = 300
+	// a client can send ChatBurst messages at once, then one more every ChatRefill
+	ChatBurst  = 5
+	ChatRefill = 2 * time.Second
+	// chat messages kept for players who join or reconnect later
+	MaxChatHistory = 100
+)
+
+// chatBucket is a client's token bucket for chat, guarded by the client's mu
+type chatBucket struct {
+	tokens     float64
+	refilledAt time.Time
+}
+
+// takeChatToken is false once the client has used up its burst, the bucket
+// refills one message every ChatRefill
+func (c *Client) takeChatToken(now time.Time) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.chat.refilledAt.IsZero() {
+		c.chat.tokens = ChatBurst
+	} else {
+		c.chat.tokens += float64(now.Sub(c.chat.refilledAt)) / float64(ChatRefill)
+		if c.chat.tokens > ChatBurst {
+			c.chat.tokens = ChatBurst
+		}
+	}
+	c.chat.refilledAt = now
+	if c.chat.tokens < 1 {
+		return false
+	}
+	c.chat.tokens--
+	return true
+}
+
+func (c *Client) getChatBucket() chatBucket {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.chat
+}
+
+func (c *Client) setChatBucket(bucket chatBucket) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.chat = bucket
+}
+
+// handleClientChat sends a player's chat to everyone, stamped with who sent
+// it and when so nobody can speak for someone else
+func (director *GameDirector) handleClientChat(clientID string, chatMsg *models.ChatMessageJson) error {
+	client, ok := director.Clients[clientID]
+	if !ok {
+		return errors.New(fmt.Sprintf("No client with id: %s. Must provide valid client ID", clientID))
+	}
+	if director.isMuted(clientID) {
+		return newClientError(models.ErrMuted, "the host muted you")
+	}
+	text := strings.TrimSpace(chatMsg.Text)
+	if text == "" || utf8.RuneCountInString(text) > MaxChatMessageLength {
+		return errors.New(fmt.Sprintf("chat messages must be 1 to %d characters", MaxChatMessageLength))
+	}
+	if !client.takeChatToken(time.Now()) {
+		return newClientError(models.ErrRateLimited, "slow down, you are sending chat too fast")
+	}
+
+	director.adminMu.Lock()
+	director.lastChatId++
+	id := director.lastChatId
+	director.adminMu.Unlock()
+	director.broadcast(models.NewMessage(models.ChatMessage, &models.ChatMessageJson{
+		Id:         id,
+		SenderId:   clientID,
+		SenderName: director.getPlayerName(clientID),
+		Text:       text,
+		SentAt:     time.Now().UTC(),
+	}))
+	return nil
+}
+
+// getPlayerName is the name the roster shows for the player
+func (director *GameDirector) getPlayerName(clientID string) string {
+	for _, player := range director.getRoster().Players {
+		if player.Id == clientID {
+			return player.Name
+		}
+	}
+	return ""
+}
+
+func (director *GameDirector) isMuted(clientID string) bool {
+	director.adminMu.Lock()
+	defer director.adminMu.Unlock()
+	return director.muted[clientID]
+}
+
+func (director *GameDirector) handleHostMutePlayer(hostID string, muteMsg *models.MutePlayerJson) error {
+	if muteMsg.PlayerId == hostID {
+		return errors.New("the host cannot mute themselves")
+	}
+	if !director.isExistingClient(muteMsg.PlayerId) {
+		return errors.New(fmt.Sprintf("no player with id: %s", muteMsg.PlayerId))
+	}
+	var addr string
+	if client, ok := director.Clients[muteMsg.PlayerId]; ok && director.chatLimitsByAddress {
+		addr = client.remoteAddr
+	}
+	director.adminMu.Lock()
+	if muteMsg.Muted {
+		director.muted[muteMsg.PlayerId] = true
+		if addr != "" {
+			director.mutedAddrs[addr] = true
+		}
+	} else {
+		delete(director.muted, muteMsg.PlayerId)
+		delete(director.mutedAddrs, addr)
+	}
+	director.adminMu.Unlock()
+	director.sendRoster()
+	return nil
+}
+
+// addToHistory keeps a message for players who join later, only the latest
+// MaxChatHistory chat messages are kept. It runs on the Listen loop. The
+// history is never changed in place, joiners and snapshots share it as is.
+func (director *GameDirector) addToHistory(msg *models.Message) {
+	history := make([]*models.Message, 0, len(director.messages)+1)
+	history = append(append(history, director.messages...), msg)
+	if msg.Type != models.ChatMessage {
+		director.messages = history
+		return
+	}
+	chats := 0
+	for _, m := range history {
+		if m.Type == models.ChatMessage {
+			chats++
+		}
+	}
+	kept := make([]*models.Message, 0, len(history))
+	for _, m := range history {
+		if m.Type == models.ChatMessage && chats > MaxChatHistory {
+			// the oldest chat goes first
+			chats--
+			continue
+		}
+		kept = append(kept, m)
+	}
+	director.messages = kept
+}
+
+// retractChat takes a chat message out of the history and tells everyone to
+// drop it. It runs on the Listen loop, which owns the history.
+func (director *GameDirector) retractChat(messageID int) error {
+	for i, msg := range director.messages {
+		if msg.Type != models.ChatMessage {
+			continue
+		}
+		var chat models.ChatMessageJson
+		if err := msg.Decode(&chat); err != nil || chat.Id != messageID {
+			continue
+		}
+		history := make([]*models.Message, 0, len(director.messages)-1)
+		director.messages = append(append(history, director.messages[:i]...), director.messages[i+1:]...)
+		director.sendAll(models.NewMessage(models.ChatRetracted, &models.RetractChatJson{MessageId: messageID}))
+		if director.phase != models.PhaseLobby {
+			// a lobby restores as a game that already started, it isn't saved
+			director.saveSnapshot()
+		}
+		return nil
+	}
+	return errors.New(fmt.Sprintf("no chat message with id %d", messageID))
+}
+
+// inheritChatLimits carries the chat bucket of the last player to join from
+// c's address over to c, and keeps c muted if the host muted that address, so
+// clearing the draft cookie to come back as someone new shakes off neither.
+// Players behind one proxy or NAT share an address and would share the mute
+// and bucket too, so it only applies when the server opts in to it. Otherwise
+// limits follow the player's cookie or join token. It runs on the Listen loop.
+func (director *GameDirector) inheritChatLimits(c *Client) {
+	if !director.chatLimitsByAddress || c.remoteAddr == "" {
+		return
+	}
+	if last, ok := director.lastClientByAddr[c.remoteAddr]; ok && last != c {
+		c.setChatBucket(last.getChatBucket())
+	}
+	director.lastClientByAddr[c.remoteAddr] = c
+	director.adminMu.Lock()
+	defer director.adminMu.Unlock()
+	if director.mutedAddrs[c.remoteAddr] {
+		director.muted[c.Id] = true
+	}
+}
@@ -0,0 +1,210 @@
+package director_test
+
+import (
+	"github.com/gorilla/websocket"
+	"github.com/malexanderboyd/pwr9-godr4ft/internal/director"
+	"github.com/malexanderboyd/pwr9-godr4ft/internal/director/models"
+	"github.com/malexanderboyd/pwr9-godr4ft/internal/game"
+	"io/ioutil"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestChatModeration(t *testing.T) {
+	var options game.GeneralOptions
+	d := director.NewGameDirector(options, 9000, "chat_game")
+	go d.Listen()
+	defer d.Finish()
+
+	ts := httptest.NewServer(d.Handler())
+	defer ts.Close()
+	wsUrl := "ws" + strings.TrimPrefix(ts.URL, "http") + "/ws"
+
+	host, _, err := websocket.DefaultDialer.Dial(wsUrl, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer host.Close()
+	director.ReadUntil(t, host, models.HostChange)
+	player, _, err := websocket.DefaultDialer.Dial(wsUrl, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer player.Close()
+	director.ReadUntil(t, player, models.NewPlayer)
+	var hostID, playerID string
+	d.RunOnLoop(func() {
+		hostID = d.Host()
+		for id := range d.Clients {
+			if id != hostID {
+				playerID = id
+			}
+		}
+	})
+
+	_ = player.WriteJSON(models.NewMessage(models.SetName, &models.SetNameJson{Name: "Spammer"}))
+	_ = player.WriteJSON(models.NewMessage(models.ChatMessage, &models.ChatMessageJson{Text: " hi ", SenderId: "someone else"}))
+	var chat models.ChatMessageJson
+	_ = director.ReadUntil(t, host, models.ChatMessage).Decode(&chat)
+	if chat.Id == 0 || chat.SenderId != playerID || chat.SenderName != "Spammer" || chat.Text != "hi" || chat.SentAt.IsZero() {
+		t.Errorf("expected the server to stamp the message, got %+v", chat)
+	}
+
+	_ = player.WriteJSON(models.NewMessage(models.ChatMessage, &models.ChatMessageJson{Text: strings.Repeat("a", director.MaxChatMessageLength+1)}))
+	if code := director.ReadError(t, player); code != models.ErrRejected {
+		t.Errorf("expected an overlong message to be rejected, got %q", code)
+	}
+
+	for i := 1; i < director.ChatBurst; i++ {
+		_ = player.WriteJSON(models.NewMessage(models.ChatMessage, &models.ChatMessageJson{Text: "spam"}))
+	}
+	_ = player.WriteJSON(models.NewMessage(models.ChatMessage, &models.ChatMessageJson{Text: "spam"}))
+	if code := director.ReadError(t, player); code != models.ErrRateLimited {
+		t.Errorf("expected chat past the burst to be rate limited, got %q", code)
+	}
+	// someone else on the same address has their own bucket
+	neighbour, _, err := websocket.DefaultDialer.Dial(wsUrl, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer neighbour.Close()
+	_ = neighbour.WriteJSON(models.NewMessage(models.ChatMessage, &models.ChatMessageJson{Text: "hello table"}))
+	readChat(t, host, "hello table")
+
+	_ = host.WriteJSON(models.NewMessage(models.RetractChat, &models.RetractChatJson{MessageId: chat.Id}))
+	var retracted models.RetractChatJson
+	_ = director.ReadUntil(t, player, models.ChatRetracted).Decode(&retracted)
+	if retracted.MessageId != chat.Id {
+		t.Errorf("expected message %d to be retracted, got %d", chat.Id, retracted.MessageId)
+	}
+
+	_ = player.WriteJSON(models.NewMessage(models.MutePlayer, &models.MutePlayerJson{PlayerId: hostID, Muted: true}))
+	if code := director.ReadError(t, player); code != models.ErrUnauthorized {
+		t.Errorf("expected only the host to mute, got %q", code)
+	}
+	_ = host.WriteJSON(models.NewMessage(models.MutePlayer, &models.MutePlayerJson{PlayerId: playerID, Muted: true}))
+	director.ReadUntil(t, player, models.Roster)
+	_ = player.WriteJSON(models.NewMessage(models.ChatMessage, &models.ChatMessageJson{Text: "let me talk"}))
+	if code := director.ReadError(t, player); code != models.ErrMuted {
+		t.Errorf("expected a muted player's chat to be turned down, got %q", code)
+	}
+
+	late, _, err := websocket.DefaultDialer.Dial(wsUrl, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer late.Close()
+	var first models.ChatMessageJson
+	_ = director.ReadUntil(t, late, models.ChatMessage).Decode(&first)
+	if first.Id == chat.Id {
+		t.Errorf("expected a retracted message to be left out of the history")
+	}
+	// the mute is on the player, not everyone behind their address
+	_ = late.WriteJSON(models.NewMessage(models.ChatMessage, &models.ChatMessageJson{Text: "just arrived"}))
+	readChat(t, host, "just arrived")
+}
+
+func TestChatLimitsByAddress(t *testing.T) {
+	var options game.GeneralOptions
+	d := director.NewGameDirector(options, 9000, "chat_address_game")
+	d.SetChatLimitsByAddress(true)
+	go d.Listen()
+	defer d.Finish()
+
+	ts := httptest.NewServer(d.Handler())
+	defer ts.Close()
+	wsUrl := "ws" + strings.TrimPrefix(ts.URL, "http") + "/ws"
+
+	host, _, err := websocket.DefaultDialer.Dial(wsUrl, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer host.Close()
+	director.ReadUntil(t, host, models.HostChange)
+	player, _, err := websocket.DefaultDialer.Dial(wsUrl, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer player.Close()
+	director.ReadUntil(t, player, models.NewPlayer)
+	var playerID string
+	d.RunOnLoop(func() {
+		for id := range d.Clients {
+			if id != d.Host() {
+				playerID = id
+			}
+		}
+	})
+
+	for i := 0; i < director.ChatBurst; i++ {
+		_ = player.WriteJSON(models.NewMessage(models.ChatMessage, &models.ChatMessageJson{Text: "spam"}))
+	}
+	_ = player.WriteJSON(models.NewMessage(models.ChatMessage, &models.ChatMessageJson{Text: "spam"}))
+	if code := director.ReadError(t, player); code != models.ErrRateLimited {
+		t.Errorf("expected chat past the burst to be rate limited, got %q", code)
+	}
+	// coming back without the draft cookie doesn't refill the bucket
+	fresh, _, err := websocket.DefaultDialer.Dial(wsUrl, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fresh.Close()
+	_ = fresh.WriteJSON(models.NewMessage(models.ChatMessage, &models.ChatMessageJson{Text: "spam"}))
+	if code := director.ReadError(t, fresh); code != models.ErrRateLimited {
+		t.Errorf("expected a new connection from the spammer's address to stay rate limited, got %q", code)
+	}
+
+	_ = host.WriteJSON(models.NewMessage(models.MutePlayer, &models.MutePlayerJson{PlayerId: playerID, Muted: true}))
+	director.ReadUntil(t, player, models.Roster)
+	late, _, err := websocket.DefaultDialer.Dial(wsUrl, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer late.Close()
+	director.ReadUntil(t, late, models.NewPlayer)
+	_ = late.WriteJSON(models.NewMessage(models.ChatMessage, &models.ChatMessageJson{Text: "it's me again"}))
+	if code := director.ReadError(t, late); code != models.ErrMuted {
+		t.Errorf("expected a new connection from a muted address to start muted, got %q", code)
+	}
+}
+
+func TestRetractingLobbyChatIsNotSnapshotted(t *testing.T) {
+	dir, err := ioutil.TempDir("", "godr4ft")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	var options game.GeneralOptions
+	d := director.NewGameDirector(options, 9000, "lobby_retract_game")
+	host, _ := director.NewClient(d)
+	d.Clients[host.Id] = host
+	d.SetHost(host.Id)
+	path := filepath.Join(dir, "snapshot.json")
+	d.SetSnapshotPath(path, time.Millisecond)
+
+	d.HandleClientMessage(host.Id, models.NewMessage(models.ChatMessage, &models.ChatMessageJson{Text: "oops"}))
+	var chat models.ChatMessageJson
+	_ = director.NextMessageOfType(t, host, models.ChatMessage, 0).Decode(&chat)
+	d.HandleClientMessage(host.Id, models.NewMessage(models.RetractChat, &models.RetractChatJson{MessageId: chat.Id}))
+	if director.NextMessageOfType(t, host, models.ChatRetracted, 0) == nil {
+		t.Fatalf("expected the chat to be retracted")
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("expected no snapshot of a lobby, it would restore as a started game")
+	}
+}
+
+// readChat reads ws up to the chat message with text
+func readChat(t *testing.T, ws *websocket.Conn, text string) {
+	var chat models.ChatMessageJson
+	for chat.Text != text {
+		if err := director.ReadUntil(t, ws, models.ChatMessage).Decode(&chat); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
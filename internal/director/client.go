@@ -1,79 +1,214 @@
 package director
 
 import (
+	"crypto/rand"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"github.com/gorilla/websocket"
 	"github.com/malexanderboyd/pwr9-godr4ft/internal"
 	"github.com/malexanderboyd/pwr9-godr4ft/internal/director/models"
+	"sync"
+	"sync/atomic"
 	"time"
 )
 
-var maxId = -1
-
 type Client struct {
-	Id        string
+	Id string
+	// seatToken is the secret the draft cookie holds to get back into the seat,
+	// the id is public so it can't be the cookie
+	seatToken string
 	director  *GameDirector
 	Websocket *websocket.Conn
 	ch        chan *models.Message
 	messages  []*models.Message
 	doneCh    chan bool
+	doneOnce  *sync.Once
+	connected bool
+	mu        sync.Mutex
 	pool      []models.SetCard
+	deck      models.Deck
+	// spectators watch the draft, they never take a seat or become host
+	spectator bool
+	name      string
+	ready     bool
+	// protocolVersion is what the client agreed to in its hello, 0 until then
+	protocolVersion int
+	chat            chatBucket
+	// the address the client joined from, without its port
+	remoteAddr string
+}
+
+// clientConn is the state of a single websocket connection, a client gets a
+// new one every time it reconnects.
+type clientConn struct {
+	ws       *websocket.Conn
+	ch       chan *models.Message
+	doneCh   chan bool
+	doneOnce *sync.Once
+}
+
+func (conn *clientConn) close() {
+	conn.doneOnce.Do(func() {
+		close(conn.doneCh)
+	})
 }
 
 func NewClient(director *GameDirector) (*Client, error) {
 	if director == nil {
 		return nil, errors.New("cannot add client with nil GameDirector")
 	}
-	maxId++
-	clientID := fmt.Sprintf("%s_%d", director.GameId, maxId)
+	clientID := fmt.Sprintf("%s_%d", director.GameId, atomic.AddInt64(&director.clientCount, 1))
 
 	ch := make(chan *models.Message, models.ChannelBufSize)
 	doneCh := make(chan bool)
 
-	return &Client{clientID, director, nil, ch, []*models.Message{}, doneCh, nil}, nil
+	return &Client{
+		Id:        clientID,
+		director:  director,
+		ch:        ch,
+		messages:  []*models.Message{},
+		doneCh:    doneCh,
+		doneOnce:  &sync.Once{},
+		connected: true,
+	}, nil
+}
+
+// newSeatToken is a random, unguessable value for a draft cookie
+func newSeatToken() string {
+	token := make([]byte, 16)
+	if _, err := rand.Read(token); err != nil {
+		panic(fmt.Sprintf("cannot read random seat token: %s", err.Error()))
+	}
+	return hex.EncodeToString(token)
+}
+
+func (c *Client) getName() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.name
+}
+
+func (c *Client) setName(name string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.name = name
+}
+
+func (c *Client) getProtocolVersion() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.protocolVersion
+}
+
+func (c *Client) setProtocolVersion(version int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.protocolVersion = version
+}
+
+func (c *Client) isReady() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.ready
+}
+
+func (c *Client) setReady(ready bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.ready = ready
+}
+
+// Reconnect attaches a new websocket to a client whose previous connection
+// dropped, the client keeps its id, seat and pool.
+func (c *Client) Reconnect(ws *websocket.Conn) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.Websocket = ws
+	c.ch = make(chan *models.Message, models.ChannelBufSize)
+	c.doneCh = make(chan bool)
+	c.doneOnce = &sync.Once{}
+	c.connected = true
+}
+
+func (c *Client) IsConnected() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.connected
+}
+
+func (c *Client) currentConn() *clientConn {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return &clientConn{
+		ws:       c.Websocket,
+		ch:       c.ch,
+		doneCh:   c.doneCh,
+		doneOnce: c.doneOnce,
+	}
+}
+
+// disconnect marks the client as disconnected if conn is still its current
+// connection, it reports false when a newer connection has replaced conn.
+func (c *Client) disconnect(conn *clientConn) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.Websocket != conn.ws {
+		return false
+	}
+	c.connected = false
+	return true
 }
 
 func (c *Client) Write(msg *models.Message) {
+	if !c.IsConnected() {
+		// disconnected clients are caught up when they reconnect
+		return
+	}
+
+	conn := c.currentConn()
 	select {
-	case c.ch <- msg:
+	case conn.ch <- msg:
 	default:
-		c.Done()
+		bufferFullDisconnects.Inc()
+		conn.close()
 	}
 }
 
 func (c *Client) Listen() {
-	go c.listenWrite()
-	c.listenRead()
+	conn := c.currentConn()
+	connectedClients.Inc()
+	go c.listenWrite(conn)
+	c.listenRead(conn)
 }
 
-func (c *Client) listenRead() {
-	c.Websocket.SetReadLimit(models.MaxMessageSize)
-	c.Websocket.SetReadDeadline(time.Now().Add(models.PongWait))
-	c.Websocket.SetPongHandler(func(string) error { c.Websocket.SetReadDeadline(time.Now().Add(models.PongWait)); return nil })
+func (c *Client) listenRead(conn *clientConn) {
+	conn.ws.SetReadLimit(models.MaxMessageSize)
+	conn.ws.SetReadDeadline(time.Now().Add(models.PongWait))
+	conn.ws.SetPongHandler(func(string) error { conn.ws.SetReadDeadline(time.Now().Add(models.PongWait)); return nil })
 	logger := internal.GetLogger()
 	logger.Debugw("listening to read", "client", c.Id)
 	for {
 		select {
-		case <-c.doneCh:
+		case <-conn.doneCh:
 			logger.Debugw("client done reading", "client", c.Id)
 			return
 		default:
 			var msg models.Message
-			_, msgContent, err := c.Websocket.ReadMessage()
+			_, msgContent, err := conn.ws.ReadMessage()
 			if err != nil {
 				if !websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway) {
 					c.director.Error(err)
 				}
-				c.Done()
+				conn.close()
 			}
 			if msgContent != nil {
+				// messages are handled on the Listen loop, it owns the game's state
 				if err := json.Unmarshal(msgContent, &msg); err != nil {
-					c.director.Error(err)
-					c.Done()
+					c.director.runOnLoop(func() { c.director.writeError(c.Id, "", models.ErrMalformedMessage, err) })
 				} else {
-					c.director.HandleClientMessage(c.Id, &msg)
+					c.director.runOnLoop(func() { c.director.HandleClientMessage(c.Id, &msg) })
 				}
 
 			}
@@ -81,52 +216,147 @@ func (c *Client) listenRead() {
 	}
 }
 
-func (c *Client) listenWrite() {
+func (c *Client) listenWrite(conn *clientConn) {
 	logger := internal.GetLogger()
 	logger.Debugw("listening to write", "client", c.Id)
 	ticker := time.NewTicker(models.PingPeriod)
 	defer func() {
+		connectedClients.Dec()
 		ticker.Stop()
-		c.Websocket.Close()
-		c.director.DeleteClient(c)
-		close(c.ch)
+		conn.ws.Close()
+		if c.disconnect(conn) {
+			c.director.DeleteClient(c)
+		}
 	}()
 	for {
 		select {
-		case msg := <-c.ch:
-			c.Websocket.SetWriteDeadline(time.Now().Add(models.WriteWait))
-			err := c.Websocket.WriteJSON(msg)
+		case msg := <-conn.ch:
+			conn.ws.SetWriteDeadline(time.Now().Add(models.WriteWait))
+			err := conn.ws.WriteJSON(msg)
 			if err != nil {
+				wsWriteFailures.Inc()
 				c.director.Error(err)
-				c.Done()
+				conn.close()
 			}
-		case <-c.doneCh:
+		case <-conn.doneCh:
 			logger.Debugw("client done writing", "client", c.Id)
 			return
 		case <-ticker.C:
-			c.Websocket.SetWriteDeadline(time.Now().Add(models.WriteWait))
-			if err := c.Websocket.WriteMessage(websocket.PingMessage, nil); err != nil {
-				c.Done()
+			conn.ws.SetWriteDeadline(time.Now().Add(models.WriteWait))
+			if err := conn.ws.WriteMessage(websocket.PingMessage, nil); err != nil {
+				conn.close()
 			}
 		}
 	}
 }
 
+// Done closes the client's current connection, it is safe to call more than once.
 func (c *Client) Done() {
-	c.doneCh <- true
+	c.currentConn().close()
 }
 
 func (c *Client) AddCardToPool(card models.SetCard) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	c.pool = append(c.pool, card)
 }
 
+// getPool is a copy of the cards the client has picked
+func (c *Client) getPool() []models.SetCard {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]models.SetCard{}, c.pool...)
+}
+
+func (c *Client) getPoolSize() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.pool)
+}
+
+func (c *Client) getPoolMessage() *models.Message {
+	return models.NewMessage(models.PoolContent, c.getPool())
+}
+
 func (c *Client) WriteCurrentPool() {
-	poolAsJson, err := json.Marshal(c.pool)
-	if err != nil {
-		c.director.Error(err)
+	c.Write(c.getPoolMessage())
+}
+
+func (c *Client) isDeckReady() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.deck.Ready
+}
+
+func (c *Client) setDeckReady(ready bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.deck.Ready = ready
+}
+
+// startDeck puts the client's whole pool in its sideboard to build from
+func (c *Client) startDeck() {
+	c.deck = models.Deck{
+		Sideboard:  c.getPool(),
+		BasicLands: make(map[string]int),
 	}
-	c.Write(&models.Message{
-		Type: models.PoolContent,
-		Data: string(poolAsJson),
-	})
+}
+
+func (c *Client) moveCard(uuid string, toSideboard bool) error {
+	from, to := &c.deck.Main, &c.deck.Sideboard
+	if !toSideboard {
+		from, to = &c.deck.Sideboard, &c.deck.Main
+	}
+
+	for i, card := range *from {
+		if card.UUID == uuid {
+			*from = append((*from)[:i], (*from)[i+1:]...)
+			*to = append(*to, card)
+			return nil
+		}
+	}
+	return errors.New(fmt.Sprintf("[client %s] has no card %s to move", c.Id, uuid))
+}
+
+func (c *Client) setBasicLands(lands map[string]int) error {
+	for name, count := range lands {
+		if !models.IsBasicLandName(name) {
+			return errors.New(fmt.Sprintf("[client %s] asked for unknown basic land %q", c.Id, name))
+		}
+		if count < 0 || count > models.MaxBasicLands {
+			return errors.New(fmt.Sprintf("[client %s] asked for %d %s", c.Id, count, name))
+		}
+	}
+
+	c.deck.BasicLands = make(map[string]int)
+	for name, count := range lands {
+		if count > 0 {
+			c.deck.BasicLands[name] = count
+		}
+	}
+	return nil
+}
+
+func (c *Client) getDeckMessage() *models.Message {
+	return models.NewMessage(models.DeckContent, c.deck)
+}
+
+func (c *Client) WriteCurrentDeck() {
+	c.Write(c.getDeckMessage())
+}
+
+// getDeckList is the client's main deck with basic lands added and its
+// sideboard, or the whole pool as the main deck if it never built one.
+func (c *Client) getDeckList() ([]models.SetCard, []models.SetCard) {
+	if c.deck.Main == nil && c.deck.Sideboard == nil {
+		return c.getPool(), nil
+	}
+
+	main := append([]models.SetCard{}, c.deck.Main...)
+	for _, name := range models.BasicLandNames {
+		for i := 0; i < c.deck.BasicLands[name]; i++ {
+			main = append(main, models.SetCard{Name: name, Rarity: "common", Supertypes: []interface{}{"Basic"}})
+		}
+	}
+	return main, c.deck.Sideboard
 }
@@ -0,0 +1,176 @@
+package director_test
+
+import (
+	"encoding/json"
+	"github.com/gorilla/websocket"
+	"github.com/malexanderboyd/pwr9-godr4ft/internal/director"
+	"github.com/malexanderboyd/pwr9-godr4ft/internal/director/models"
+	"github.com/malexanderboyd/pwr9-godr4ft/internal/game"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+type noticeRecorder chan *models.PackWaitingNotice
+
+func (recorder noticeRecorder) NotifyPackWaiting(notice *models.PackWaitingNotice) error {
+	recorder <- notice
+	return nil
+}
+
+func (recorder noticeRecorder) next(t *testing.T) *models.PackWaitingNotice {
+	select {
+	case notice := <-recorder:
+		return notice
+	case <-time.After(time.Second):
+		t.Fatalf("expected a pack waiting notice")
+		return nil
+	}
+}
+
+func newCorrespondenceGame(t *testing.T) (*director.GameDirector, *director.Client) {
+	d, clients := director.NewSeatedCubeDraft(t, "correspondence_game", game.GeneralOptions{PickDeadlineHours: 12}, 1)
+	client := clients[0]
+	client.SetConnected(false)
+	return d, client
+}
+
+func TestCorrespondenceDeadlines(t *testing.T) {
+	d, client := newCorrespondenceGame(t)
+	notices := make(noticeRecorder, 10)
+	d.SetNotifier(notices)
+	d.DealPack()
+
+	notice := notices.next(t)
+	if notice.PlayerId != client.Id || notice.Pick != 1 {
+		t.Errorf("expected the player to be told pick 1 is waiting, got %+v", notice)
+	}
+	if until := time.Until(notice.Deadline); until < 11*time.Hour || until > 12*time.Hour {
+		t.Errorf("expected a deadline 12 hours out, got %s", until)
+	}
+
+	dir, err := ioutil.TempDir("", "godr4ft")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "snapshot.json")
+	d.AgePickTimer(0, 2*time.Hour)
+	d.SaveSnapshot(path)
+	snapshot, err := director.LoadSnapshot(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	restored := director.NewGameDirectorFromSnapshot(snapshot, 9001)
+	if remaining := restored.GetPickTimeRemaining(0); remaining < 9*time.Hour || remaining > 10*time.Hour {
+		t.Errorf("expected the restored deadline to keep running, %s left", remaining)
+	}
+
+	d.AgePickTimer(0, 10*time.Hour)
+	due := d.TickPickTimers()
+	if len(due) != 1 || due[0] != 0 {
+		t.Fatalf("expected the player's seat to be due once the deadline passed, got %v", due)
+	}
+}
+
+func TestCorrespondenceGamesResumeFromTheSnapshotDir(t *testing.T) {
+	d, _ := newCorrespondenceGame(t)
+	d.DealPack()
+	dir, err := ioutil.TempDir("", "godr4ft")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	d.SaveSnapshot(director.GetSnapshotPath(dir, d.GameId))
+
+	server := director.NewGameServer(director.ServerConfig{SnapshotDir: dir})
+	server.ResumeCorrespondenceGames()
+	resumed, ok := server.GetGame(d.GameId)
+	if !ok {
+		t.Fatalf("expected the correspondence draft to be resumed from %s", dir)
+	}
+	resumed.Finish()
+}
+
+func TestCorrespondenceDrafterRejoinsHoursLater(t *testing.T) {
+	options := game.GeneralOptions{TotalPlayers: 2, Type: game.DRAFT, Mode: game.CUBE, PickDeadlineHours: 12}
+	options.GameOptions.Draft.Cube = game.DraftCubeOptions{CardsPerPack: 3, TotalPacks: 1, CubeList: director.SixCardCube}
+	d := director.NewGameDirector(options, 9000, "correspondence_cookie_game")
+	if err := d.GetGameResources(); err != nil {
+		t.Fatal(err)
+	}
+	go d.Listen()
+	defer d.Finish()
+	ts := httptest.NewServer(d.Handler())
+	defer ts.Close()
+	wsUrl := "ws" + strings.TrimPrefix(ts.URL, "http") + "/ws"
+
+	host, _, err := websocket.DefaultDialer.Dial(wsUrl, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer host.Close()
+	director.ReadUntil(t, host, models.HostChange)
+	player, res, err := websocket.DefaultDialer.Dial(wsUrl, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cookies := res.Cookies()
+	if len(cookies) != 1 {
+		t.Fatalf("expected a draft cookie, got %v", cookies)
+	}
+	// three picks of up to 12 hours each, well past the default half hour
+	if returnsAt := time.Now().Add(30 * time.Hour); cookies[0].Expires.Before(returnsAt) {
+		t.Errorf("expected the cookie to outlast the draft's deadlines, it expires %s", cookies[0].Expires)
+	}
+
+	_ = host.WriteJSON(models.NewMessage(models.GameStart, &models.TimerSettings{}))
+	pickFirstCard(t, player)
+	player.Close()
+
+	header := http.Header{}
+	header.Set("Cookie", cookies[0].Name+"="+cookies[0].Value)
+	player, res, err = websocket.DefaultDialer.Dial(wsUrl, header)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer player.Close()
+	if rejoined := res.Cookies(); len(rejoined) != 1 || rejoined[0].Value != cookies[0].Value {
+		t.Errorf("expected the drafter back in their seat, got %v", rejoined)
+	}
+	var pool []models.SetCard
+	if err := director.ReadUntil(t, player, models.PoolContent).Decode(&pool); err != nil || len(pool) != 1 {
+		t.Errorf("expected the drafter's pick back in their pool, got %v %v", pool, err)
+	}
+}
+
+func TestWebhookNotifier(t *testing.T) {
+	received := make(chan models.PackWaitingNotice, 1)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var notice models.PackWaitingNotice
+		_ = json.NewDecoder(r.Body).Decode(&notice)
+		received <- notice
+	}))
+	defer ts.Close()
+
+	notifier := director.NewWebhookNotifier(ts.URL)
+	if err := notifier.NotifyPackWaiting(&models.PackWaitingNotice{GameId: "game", PlayerId: "player", Pick: 3}); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if notice := <-received; notice.PlayerId != "player" || notice.Pick != 3 {
+		t.Errorf("expected the notice to be posted, got %+v", notice)
+	}
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer failing.Close()
+	if err := director.NewWebhookNotifier(failing.URL).NotifyPackWaiting(&models.PackWaitingNotice{}); err == nil {
+		t.Errorf("expected a failing webhook to be an error")
+	}
+}
@@ -0,0 +1,100 @@
+package director
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"github.com/malexanderboyd/pwr9-godr4ft/internal/director/models"
+	"math/rand"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const CubeSetAbbreviation = "CUBE"
+
+// matches an optional leading count, the card name, an optional set code in
+// parens or brackets and an optional trailing collector number, ie:
+// "1x Lightning Bolt (M11) 146". The count is either "Nx" or a bare number of
+// at most two digits, so names like "1996 World Champion" stay whole.
+var cubeLineRegex = regexp.MustCompile(`^(?:(\d+)x\s+|(\d{1,2})\s+)?(.+?)(?:\s+[(\[]([A-Za-z0-9]+)[)\]](?:\s+(\S+))?)?$`)
+
+// ParseCubeList turns a cube list, one card per line, into cards. Counts expand
+// into multiple copies; blank lines and lines starting with # or // are skipped.
+func ParseCubeList(cubeList string) ([]models.SetCard, error) {
+	var cards []models.SetCard
+	scanner := bufio.NewScanner(strings.NewReader(cubeList))
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "//") {
+			continue
+		}
+
+		matches := cubeLineRegex.FindStringSubmatch(line)
+		if matches == nil {
+			return nil, errors.New(fmt.Sprintf("cannot parse cube list line %d: %q", lineNumber, line))
+		}
+
+		count := 1
+		if countText := matches[1] + matches[2]; countText != "" {
+			var err error
+			if count, err = strconv.Atoi(countText); err != nil || count < 1 {
+				return nil, errors.New(fmt.Sprintf("invalid card count on cube list line %d: %q", lineNumber, line))
+			}
+		}
+
+		for i := 0; i < count; i++ {
+			card := models.SetCard{
+				Name:   matches[3],
+				Number: matches[5],
+				// cube cards have no upstream identity, number them so duplicates stay distinct
+				UUID: fmt.Sprintf("cube-%d", len(cards)),
+			}
+			if matches[4] != "" {
+				card.SetCode = strings.ToUpper(matches[4])
+				card.Printings = []string{card.SetCode}
+			}
+			cards = append(cards, card)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return cards, nil
+}
+
+// buildCubeRounds shuffles the cube and deals cardsPerPack sized packs to every
+// seat for every pack round.
+func buildCubeRounds(cube []models.SetCard, totalSeats, totalPacks, cardsPerPack int, rng *rand.Rand) (map[int]models.DraftRound, error) {
+	if totalSeats < 1 || totalPacks < 1 || cardsPerPack < 1 {
+		return nil, errors.New(fmt.Sprintf("invalid cube settings seats=%d packs=%d cardsPerPack=%d", totalSeats, totalPacks, cardsPerPack))
+	}
+
+	required := totalSeats * totalPacks * cardsPerPack
+	if len(cube) < required {
+		return nil, errors.New(fmt.Sprintf("cube has %d cards, %d are needed for %d players", len(cube), required, totalSeats))
+	}
+
+	shuffled := make([]models.SetCard, len(cube))
+	copy(shuffled, cube)
+	rng.Shuffle(len(shuffled), func(i, j int) {
+		shuffled[i], shuffled[j] = shuffled[j], shuffled[i]
+	})
+
+	rounds := make(map[int]models.DraftRound)
+	next := 0
+	for packNumber := 0; packNumber < totalPacks; packNumber++ {
+		playerPacks := make(map[int][]models.SetCard)
+		for seat := 0; seat < totalSeats; seat++ {
+			playerPacks[seat] = shuffled[next : next+cardsPerPack : next+cardsPerPack]
+			next += cardsPerPack
+		}
+		rounds[packNumber] = models.DraftRound{
+			SetAbbreviation: CubeSetAbbreviation,
+			PlayerPacks:     playerPacks,
+		}
+	}
+	return rounds, nil
+}
@@ -0,0 +1,67 @@
+package director_test
+
+import (
+	"github.com/malexanderboyd/pwr9-godr4ft/internal/director"
+	"github.com/malexanderboyd/pwr9-godr4ft/internal/director/models"
+	"strings"
+	"testing"
+)
+
+var exportMain = []models.SetCard{
+	{Name: "Llanowar Elves", SetCode: "DOM", Number: "168", MtgoID: 67268},
+	{Name: "Shivan Fire", SetCode: "DOM", Number: "142", MtgoID: 67216},
+	{Name: "Llanowar Elves", SetCode: "DOM", Number: "168", MtgoID: 67268},
+	{Name: "Black Lotus"},
+}
+
+var exportSideboard = []models.SetCard{
+	{Name: "Shivan Fire", SetCode: "DOM", Number: "142", MtgoID: 67216},
+}
+
+func TestExportDeckText(t *testing.T) {
+	contents, err := director.ExportDeck(exportMain, exportSideboard, director.TextExportFormat)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	expected := "2 Llanowar Elves\n1 Shivan Fire\n1 Black Lotus\n\n1 Shivan Fire\n"
+	if string(contents) != expected {
+		t.Errorf("expected\n%s\ngot\n%s", expected, contents)
+	}
+}
+
+func TestExportDeckArena(t *testing.T) {
+	contents, err := director.ExportDeck(exportMain, exportSideboard, director.ArenaExportFormat)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	expected := "Deck\n2 Llanowar Elves (DAR) 168\n1 Shivan Fire (DAR) 142\n1 Black Lotus\n\nSideboard\n1 Shivan Fire (DAR) 142\n"
+	if string(contents) != expected {
+		t.Errorf("expected\n%s\ngot\n%s", expected, contents)
+	}
+}
+
+func TestExportDeckMtgo(t *testing.T) {
+	contents, err := director.ExportDeck(exportMain, exportSideboard, director.MtgoExportFormat)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	for _, line := range []string{
+		`<?xml version="1.0" encoding="UTF-8"?>`,
+		`<Cards CatID="67268" Quantity="2" Sideboard="false" Name="Llanowar Elves"></Cards>`,
+		`<Cards Quantity="1" Sideboard="false" Name="Black Lotus"></Cards>`,
+		`<Cards CatID="67216" Quantity="1" Sideboard="true" Name="Shivan Fire"></Cards>`,
+	} {
+		if !strings.Contains(string(contents), line) {
+			t.Errorf("expected .dek to contain %s, got\n%s", line, contents)
+		}
+	}
+}
+
+func TestExportDeckUnknownFormat(t *testing.T) {
+	if _, err := director.ExportDeck(exportMain, nil, "mws"); err == nil {
+		t.Errorf("expected an error for an unknown export format")
+	}
+}
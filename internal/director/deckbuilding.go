@@ -0,0 +1,167 @@
+package director
+
+import (
+	"errors"
+	"fmt"
+	"github.com/malexanderboyd/pwr9-godr4ft/internal"
+	"github.com/malexanderboyd/pwr9-godr4ft/internal/director/models"
+	"time"
+)
+
+func (director *GameDirector) startDeckbuilding() {
+	logger := internal.GetLogger()
+	logger.Infow("Starting deckbuilding", "game", director.GameId)
+	director.phase = models.PhaseDeckbuilding
+	director.broadcast(models.NewMessage(models.PhaseChange, director.phase))
+
+	for clientID := range director.Seats {
+		if client, ok := director.Clients[clientID]; ok {
+			client.startDeck()
+			client.WriteCurrentDeck()
+		}
+	}
+	director.saveSnapshot()
+	director.startDeckbuildingTicker()
+}
+
+func (director *GameDirector) getSeatedClient(clientID string) (*Client, error) {
+	client := director.Clients[clientID]
+	if _, seated := director.Seats[clientID]; client == nil || !seated {
+		return nil, errors.New(fmt.Sprintf("No seated client with id: %s. Must provide valid client ID", clientID))
+	}
+	if client.deck.Locked {
+		return nil, errors.New(fmt.Sprintf("client %s changed a locked deck", clientID))
+	}
+	return client, nil
+}
+
+func (director *GameDirector) handleClientMoveCard(clientID string, moveCardMsg *models.MoveCardJson) error {
+	client, err := director.getSeatedClient(clientID)
+	if err != nil {
+		return err
+	}
+
+	if err := client.moveCard(moveCardMsg.UUID, moveCardMsg.ToSideboard); err != nil {
+		return err
+	}
+	client.WriteCurrentDeck()
+	return nil
+}
+
+func (director *GameDirector) handleClientSetBasicLands(clientID string, lands map[string]int) error {
+	client, err := director.getSeatedClient(clientID)
+	if err != nil {
+		return err
+	}
+
+	if err := client.setBasicLands(lands); err != nil {
+		return err
+	}
+	client.WriteCurrentDeck()
+	return nil
+}
+
+func (director *GameDirector) handleClientDeckReady(clientID string, deckReadyMsg *models.DeckReadyJson) error {
+	client, err := director.getSeatedClient(clientID)
+	if err != nil {
+		return err
+	}
+
+	client.setDeckReady(deckReadyMsg.Ready)
+	client.WriteCurrentDeck()
+	return nil
+}
+
+// handleHostDeckbuildingTimer starts the deckbuilding clock, HandleClientMessage
+// has already checked the sender is the host
+func (director *GameDirector) handleHostDeckbuildingTimer(timerMsg *models.DeckbuildingTimerJson) error {
+	if timerMsg.Seconds < 0 {
+		return errors.New(fmt.Sprintf("invalid deckbuilding timer %d", timerMsg.Seconds))
+	}
+
+	director.deckbuildingTimer = time.Duration(timerMsg.Seconds) * time.Second
+	director.deckbuildingTimerStartedAt = time.Now()
+	director.broadcast(director.getDeckbuildingTimerMessage())
+	return nil
+}
+
+func (director *GameDirector) isDeckbuildingTimerEnabled() bool {
+	return director.deckbuildingTimer > 0
+}
+
+func (director *GameDirector) getDeckbuildingTimeRemaining() time.Duration {
+	remaining := director.deckbuildingTimer - time.Since(director.deckbuildingTimerStartedAt)
+	if remaining < 0 {
+		return 0
+	}
+	return remaining
+}
+
+func (director *GameDirector) getDeckbuildingTimerMessage() *models.Message {
+	return models.NewMessage(models.DeckbuildingTimer, &models.DeckbuildingTimerJson{
+		Seconds: int(director.getDeckbuildingTimeRemaining() / time.Second),
+	})
+}
+
+// haveAllClientsReadiedDecks waits on disconnected players too, they may be
+// reconnecting or, in a correspondence draft, not back yet. The host's
+// deckbuilding timer is what stops the game waiting on them forever.
+func (director *GameDirector) haveAllClientsReadiedDecks() bool {
+	for clientID := range director.Seats {
+		if client, ok := director.Clients[clientID]; ok && !client.isDeckReady() {
+			return false
+		}
+	}
+	return true
+}
+
+// startDeckbuildingTicker locks decks once every player is ready or the
+// host's deckbuilding timer runs out, the check runs on the Listen loop.
+func (director *GameDirector) startDeckbuildingTicker() {
+	ticker := time.NewTicker(1 * time.Second)
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-director.finishedCh:
+				return
+			case <-ticker.C:
+				var done bool
+				if !director.runOnLoop(func() { done = director.checkDecks() }) || done {
+					return
+				}
+			}
+		}
+	}()
+}
+
+// checkDecks locks decks and starts the tournament when deckbuilding is over,
+// it reports true once there is nothing left to wait for
+func (director *GameDirector) checkDecks() bool {
+	logger := internal.GetLogger()
+	if director.phase != models.PhaseDeckbuilding {
+		// the host ended the game
+		return true
+	}
+	if director.isDeckbuildingTimerEnabled() && director.getDeckbuildingTimeRemaining() == 0 {
+		logger.Infow("Times Up! Locking decks", "game", director.GameId)
+	} else if director.haveAllClientsReadiedDecks() {
+		logger.Infow("all players are ready, locking decks", "game", director.GameId)
+	} else {
+		return false
+	}
+	director.lockDecks()
+	director.startTournament()
+	return true
+}
+
+func (director *GameDirector) lockDecks() {
+	for clientID := range director.Seats {
+		if client, ok := director.Clients[clientID]; ok {
+			client.deck.Locked = true
+			client.WriteCurrentDeck()
+		}
+	}
+	director.sendAll(models.NewMessage(models.DecksLocked, len(director.Seats)))
+	director.saveSnapshot()
+}
@@ -0,0 +1,98 @@
+package director_test
+
+import (
+	"github.com/malexanderboyd/pwr9-godr4ft/internal/director"
+	"github.com/malexanderboyd/pwr9-godr4ft/internal/director/models"
+	"github.com/malexanderboyd/pwr9-godr4ft/internal/game"
+	"testing"
+)
+
+func TestClientDeckbuilding(t *testing.T) {
+	var options game.GeneralOptions
+	d := director.NewGameDirector(options, 9000, "deck_game")
+	client, err := director.NewClient(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client.AddCardToPool(models.SetCard{Name: "Shock", UUID: "shock"})
+	client.AddCardToPool(models.SetCard{Name: "Opt", UUID: "opt"})
+	client.AddCardToPool(models.SetCard{Name: "Murder", UUID: "murder"})
+
+	main, sideboard := client.DeckList()
+	if len(main) != 3 || len(sideboard) != 0 {
+		t.Errorf("expected the whole pool as the main deck before deckbuilding, got %d/%d", len(main), len(sideboard))
+	}
+
+	client.StartDeck()
+	if err := client.MoveCard("shock", false); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if err := client.MoveCard("opt", false); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if err := client.MoveCard("opt", true); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if err := client.MoveCard("opt", true); err == nil {
+		t.Errorf("expected an error moving a card that is already in the sideboard")
+	}
+
+	if err := client.SetBasicLands(map[string]int{"Mountain": 2, "Swamp": 1}); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if err := client.SetBasicLands(map[string]int{"Volcanic Island": 1}); err == nil {
+		t.Errorf("expected an error for a land that is not basic")
+	}
+	if err := client.SetBasicLands(map[string]int{"Forest": -1}); err == nil {
+		t.Errorf("expected an error for a negative land count")
+	}
+
+	main, sideboard = client.DeckList()
+	var names []string
+	for _, card := range main {
+		names = append(names, card.Name)
+	}
+	if len(main) != 4 || names[0] != "Shock" || names[1] != "Swamp" || names[2] != "Mountain" || names[3] != "Mountain" {
+		t.Errorf("expected Shock, a Swamp and two Mountains, got %v", names)
+	}
+	if len(sideboard) != 2 {
+		t.Errorf("expected 2 sideboard cards, got %d", len(sideboard))
+	}
+}
+
+func TestDisconnectedPlayerHoldsUpDeckbuildingUntilTheTimer(t *testing.T) {
+	options := game.GeneralOptions{TotalPlayers: 2, Type: game.SEALED, Mode: game.CUBE}
+	d := director.NewGameDirector(options, 9000, "deck_lock_game")
+	ready, _ := director.NewClient(d)
+	gone, _ := director.NewClient(d)
+	d.Clients[ready.Id] = ready
+	d.Clients[gone.Id] = gone
+	d.SeatClients(2)
+	ready.StartDeck()
+	gone.StartDeck()
+	gone.SetConnected(false)
+	d.SetPhase(models.PhaseDeckbuilding)
+	go d.Listen()
+	defer d.Finish()
+
+	var locked bool
+	d.RunOnLoop(func() { locked = d.CheckDecks() })
+	if locked {
+		t.Fatalf("expected decks to stay open until the players are ready")
+	}
+	d.RunOnLoop(func() {
+		d.HandleClientMessage(ready.Id, models.NewMessage(models.DeckReady, &models.DeckReadyJson{Ready: true}))
+		locked = d.CheckDecks()
+	})
+	if locked {
+		t.Fatalf("expected decks to stay open for the disconnected player to come back")
+	}
+	d.RunOnLoop(func() {
+		d.ExpireDeckbuildingTimer()
+		locked = d.CheckDecks()
+	})
+	if !locked {
+		t.Errorf("expected decks to lock once the host's timer ran out")
+	}
+}
@@ -1,7 +1,6 @@
 package director
 
 import (
-	"encoding/json"
 	"errors"
 	"fmt"
 	"github.com/gorilla/websocket"
@@ -9,11 +8,12 @@ import (
 	"github.com/malexanderboyd/pwr9-godr4ft/internal/director/models"
 	"github.com/malexanderboyd/pwr9-godr4ft/internal/director/utils"
 	"github.com/malexanderboyd/pwr9-godr4ft/internal/game"
-	"io/ioutil"
-	"log"
+	"github.com/malexanderboyd/pwr9-godr4ft/internal/tournament"
+	"math/rand"
+	"net"
 	"net/http"
 	"os"
-	"strconv"
+	"sync"
 	"time"
 )
 
@@ -21,88 +21,251 @@ type GameDirector struct {
 	clientsContents map[string][]string
 	pool            []string
 	//
-	Port               int
-	GameId             string
-	options            game.GeneralOptions
-	gameStarted        bool
-	packNumber         int
-	round              int
-	roundTimerType     string
+	Port                      int
+	GameId                    string
+	options                   game.GeneralOptions
+	gameStarted               bool
+	phase                     models.GamePhase
+	packNumber                int
+	roundTimerType            string
 	roundTimerServerForcePick bool
-	roundPacks         map[int]models.DraftRound
-	roundPicksTickerCh chan int
-	nextRoundPacks     map[int][]models.SetCard
-	totalPacks         int
-	host               string
-	Clients            map[string]*Client
-	Seats              map[string]int
+	roundPacks                map[int]models.DraftRound
+	// packs waiting in front of each seat, see queue.go
+	packQueues map[int][]*models.QueuedPack
+	// picks each seat has made from the current pack number
+	seatPicks        map[int]int
+	pickTimers       map[int]*pickTimer
+	roundTimerPaused bool
+	forceAdvance     bool
+	// guards the host controlled timer state above, kicked, muted, mutedAddrs
+	// and lastChatId
+	adminMu sync.Mutex
+	kicked  map[string]bool
+	muted   map[string]bool
+	// addresses of muted players, whoever joins from one starts out muted
+	// when chatLimitsByAddress is set
+	mutedAddrs map[string]bool
+	// carries mutes and chat buckets between players joining from the same
+	// address, see inheritChatLimits
+	chatLimitsByAddress bool
+	// the last client to join from each address, see inheritChatLimits
+	lastClientByAddr map[string]*Client
+	lastChatId       int
+	// lobby players in the order they will be seated
+	seatOrder     []string
+	lobbyMu       sync.Mutex
+	startOnce     sync.Once
+	sealedPools   map[int][]models.SetCard
+	totalPacks    int
+	host          string
+	Clients       map[string]*Client
+	Bots          map[string]*Bot
+	Seats         map[string]int
+	Spectators    map[string]*Client
+	spectatorView *models.Message
+	// numbers spectator views as they are made, under pickMu, and the last
+	// one shown, under spectatorMu
+	spectatorViewSeq   int
+	spectatorViewShown int
+	spectatorMu        sync.Mutex
 	messages           []*models.Message
 	addClientCh        chan *Client
 	delClientCh        chan *Client
+	reconnectClientCh  chan *Client
 	sendAllCh          chan *models.Message
-	startNextRoundCh   chan bool
-	doneCh             chan bool
-	errCh              chan error
+	startNextPackCh    chan bool
+	statusCh           chan chan *models.GameStatusJson
+	// work the Listen loop runs for other goroutines, see runOnLoop
+	loopCh chan func()
+	// the draft cookie's seat token for each player, seat token -> client id
+	seatTokens map[string]string
+	// ids of players with a token whose websocket is being opened, a second
+	// connection with the same token waits for the first to join
+	joining map[string]bool
+	// numbers new client ids
+	clientCount int64
+	// restored from a snapshot, Listen restarts the draft before anything else
+	resuming     bool
+	doneCh       chan bool
+	rng          *rand.Rand
+	snapshotPath string
+	// a snapshot of the picks is waiting to be saved, see savePicks
+	snapshotPending   bool
+	pickSnapshotDelay time.Duration
+	endGracePeriod    time.Duration
+	pickLog           []models.PickEvent
+	pickLogMu         sync.Mutex
+	// pickMu keeps picks from racing each other and the next pack being dealt
+	pickMu                     sync.Mutex
+	deckbuildingTimer          time.Duration
+	deckbuildingTimerStartedAt time.Time
+	tournament                 *tournament.Tournament
+	tournamentMu               sync.Mutex
+	// path the game is served under, scopes the draft cookie to this game
+	basePath string
+	// closed once the grace period is over and the game can be removed
+	finishedCh chan bool
+	// ended is false for a lobby removed for being idle
+	onFinished func(director *GameDirector, ended bool)
+	// how long a lobby and a started game nobody is connected to stay up
+	idleTimeout      time.Duration
+	abandonedTimeout time.Duration
+	// when the last player left, zero while anyone is connected
+	idleSince time.Time
+	// where options and boosters come from, nil uses the API at ApiUri
+	cardSource CardSource
+	// tells correspondence drafters a pack is waiting, nil tells nobody
+	notifier Notifier
+	// signs join tokens, private games can't be joined without one
+	joinSecret []byte
+	// origins browsers may open a websocket from, empty allows any
+	allowedOrigins []string
+	snapshotMu     sync.Mutex
 }
 
 func NewGameDirector(options game.GeneralOptions, port int, gameId string) *GameDirector {
 	return &GameDirector{
-		clientsContents:    nil,
-		roundPacks:         make(map[int]models.DraftRound),
-		pool:               nil,
-		Port:               port,
-		GameId:             gameId,
-		options:            options,
-		gameStarted:        false,
-		packNumber:         0,
-		roundTimerType:     "",
-		round:              1,
-		roundPicksTickerCh: nil,
-		Seats:              make(map[string]int),
-		nextRoundPacks:     make(map[int][]models.SetCard),
-		totalPacks:         0,
-		host:               models.NoHostSentinel,
-		Clients:            make(map[string]*Client),
-		messages:           []*models.Message{},
-		addClientCh:        make(chan *Client),
-		delClientCh:        make(chan *Client),
-		sendAllCh:          make(chan *models.Message),
-		startNextRoundCh:   make(chan bool),
-		doneCh:             make(chan bool),
-		errCh:              make(chan error),
+		clientsContents:   nil,
+		roundPacks:        make(map[int]models.DraftRound),
+		pool:              nil,
+		Port:              port,
+		GameId:            gameId,
+		options:           options,
+		gameStarted:       false,
+		phase:             models.PhaseLobby,
+		packNumber:        0,
+		roundTimerType:    "",
+		Seats:             make(map[string]int),
+		Spectators:        make(map[string]*Client),
+		kicked:            make(map[string]bool),
+		muted:             make(map[string]bool),
+		mutedAddrs:        make(map[string]bool),
+		lastClientByAddr:  make(map[string]*Client),
+		packQueues:        make(map[int][]*models.QueuedPack),
+		seatPicks:         make(map[int]int),
+		pickTimers:        make(map[int]*pickTimer),
+		sealedPools:       make(map[int][]models.SetCard),
+		totalPacks:        0,
+		host:              models.NoHostSentinel,
+		Clients:           make(map[string]*Client),
+		Bots:              make(map[string]*Bot),
+		messages:          []*models.Message{},
+		addClientCh:       make(chan *Client),
+		delClientCh:       make(chan *Client),
+		reconnectClientCh: make(chan *Client),
+		sendAllCh:         make(chan *models.Message),
+		startNextPackCh:   make(chan bool),
+		statusCh:          make(chan chan *models.GameStatusJson),
+		loopCh:            make(chan func()),
+		seatTokens:        make(map[string]string),
+		joining:           make(map[string]bool),
+		clientCount:       -1,
+		doneCh:            make(chan bool),
+		finishedCh:        make(chan bool),
+		basePath:          "/",
+		pickSnapshotDelay: PickSnapshotDelay,
+		idleTimeout:       LobbyIdleTimeout,
+		abandonedTimeout:  AbandonedGameTimeout,
+		rng:               rand.New(rand.NewSource(time.Now().UnixNano())),
 	}
 }
 
+// the channel helpers below drop their message once the game has finished,
+// nothing is listening any more and the sender would block forever.
+
 func (director *GameDirector) AddNewClient(c *Client) {
-	director.addClientCh <- c
+	select {
+	case director.addClientCh <- c:
+	case <-director.finishedCh:
+	}
 }
 
 func (director *GameDirector) DeleteClient(c *Client) {
-	director.delClientCh <- c
+	select {
+	case director.delClientCh <- c:
+	case <-director.finishedCh:
+	}
+}
+
+func (director *GameDirector) ReconnectClient(c *Client) {
+	select {
+	case director.reconnectClientCh <- c:
+	case <-director.finishedCh:
+	}
+}
+
+// runOnLoop runs fn on the Listen loop and waits for it, anything that reads
+// or changes who is in the game goes through here from other goroutines. It
+// reports false without running fn once the game has finished.
+func (director *GameDirector) runOnLoop(fn func()) bool {
+	done := make(chan bool)
+	select {
+	case director.loopCh <- func() { fn(); close(done) }:
+	case <-director.finishedCh:
+		return false
+	}
+	<-done
+	return true
 }
 
 func (director *GameDirector) shutdown() {
-	director.doneCh <- true
+	select {
+	case director.doneCh <- true:
+	case <-director.finishedCh:
+	}
 }
 
 func (director *GameDirector) Error(err error) {
-	director.errCh <- err
+	internal.GetLogger().Errorw("error occurred", "game", director.GameId, "error", err.Error())
 }
 
-func (director *GameDirector) sendPastMessages(c *Client) {
-	for _, msg := range director.messages {
-		c.Write(msg)
+func (director *GameDirector) IsFinished() bool {
+	select {
+	case <-director.finishedCh:
+		return true
+	default:
+		return false
 	}
 }
 
+// sendPastMessages catches c up on the history from its own goroutine, the
+// history is copied on write so the slice it has stays as it is
+func (director *GameDirector) sendPastMessages(c *Client) {
+	messages := director.messages
+	go func() {
+		for _, msg := range messages {
+			c.Write(msg)
+		}
+	}()
+}
+
+// SendAll broadcasts msg from outside the Listen loop, code running on the
+// loop calls broadcast
 func (director *GameDirector) SendAll(msg *models.Message) {
-	director.sendAllCh <- msg
+	select {
+	case director.sendAllCh <- msg:
+	case <-director.finishedCh:
+	}
+}
+
+// broadcast sends msg to everyone and keeps it for players who join later,
+// it runs on the Listen loop
+func (director *GameDirector) broadcast(msg *models.Message) {
+	if msg.Type != models.RoundContent {
+		internal.GetLogger().Debugw("Sending to all clients", "msg", msg)
+	}
+	if msg.Type != models.Roster {
+		// the roster is sent fresh to anyone who joins, no need to replay old ones
+		director.addToHistory(msg)
+	}
+	director.sendAll(msg)
 }
 
 func (director *GameDirector) sendAll(msg *models.Message) {
 	for _, c := range director.Clients {
 		c.Write(msg)
 	}
+	director.writeSpectators(msg)
 }
 
 func (director *GameDirector) sendHostMessage(msg *models.Message) {
@@ -113,44 +276,200 @@ func (director *GameDirector) sendHostMessage(msg *models.Message) {
 }
 
 func (director *GameDirector) newClient(w http.ResponseWriter, r *http.Request) {
-	//var hasCookie, clientID = utils.HasDraftClientIDCookie(r, models.DraftCookieName)
-	//if hasCookie && director.isExistingClien t(clientID) {
-	//	director.Clients[clientID].Done()
-	//}
-	var err error
+	claims, err := director.checkJoinToken(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+	if r.URL.Query().Get("spectate") == "1" {
+		director.newSpectator(w, r)
+		return
+	}
+	_, seatToken := utils.HasDraftClientIDCookie(r, models.DraftCookieName)
+	var existing *Client
+	var refused error
+	var cookieLifetime time.Duration
+	if !director.runOnLoop(func() {
+		existing, refused = director.admitClient(seatToken, claims)
+		cookieLifetime = director.getDraftCookieLifetime()
+	}) {
+		http.Error(w, "the game has ended", http.StatusGone)
+		return
+	}
+	if refused != nil {
+		http.Error(w, refused.Error(), http.StatusForbidden)
+		return
+	}
+	if existing != nil {
+		director.reattachClient(existing, w, r, cookieLifetime)
+		return
+	}
 	newClient, err := NewClient(director)
 	if err != nil {
 		director.Error(err)
 		return
 	}
+	if claims != nil {
+		newClient.Id = claims.getClientId()
+		setNameFromClaims(newClient, claims)
+	}
+	newClient.seatToken = newSeatToken()
+	newClient.remoteAddr = getRemoteHost(r)
+
+	DraftClientIDCookieHeader := utils.CreateDraftClientIDCookieHeader(newClient.seatToken, models.DraftCookieName, director.basePath, cookieLifetime)
+
+	ws, err := director.getUpgrader().Upgrade(w, r, DraftClientIDCookieHeader)
+	if err != nil {
+		director.runOnLoop(func() { delete(director.joining, newClient.Id) })
+		director.Error(err)
+		_, _ = fmt.Fprintf(w, err.Error())
+		return
+	}
 
-	DraftClientIDCookieHeader := utils.CreateDraftClientIDCookieHeader(newClient.Id, models.DraftCookieName)
+	newClient.Websocket = ws
+	director.AddNewClient(newClient)
+	go newClient.Listen()
+}
 
-	var upgrader = websocket.Upgrader{
+// admitClient finds the player a connection belongs to, by join token or by the
+// seat token in its draft cookie. It returns nil for someone new and runs on
+// the Listen loop.
+func (director *GameDirector) admitClient(seatToken string, claims *JoinClaims) (*Client, error) {
+	clientID := director.seatTokens[seatToken]
+	if claims != nil {
+		// the token's identity beats the cookie, it follows the player between devices
+		clientID = claims.getClientId()
+	}
+	if clientID == "" {
+		return nil, nil
+	}
+	if director.isKicked(clientID) {
+		return nil, errors.New("the host removed you from this game")
+	}
+	client, ok := director.Clients[clientID]
+	if !ok {
+		if claims == nil {
+			return nil, nil
+		}
+		if director.joining[clientID] {
+			return nil, errors.New("you are already joining this game from another connection")
+		}
+		// held until the new client is added or its websocket fails to open
+		director.joining[clientID] = true
+		return nil, nil
+	}
+	if claims != nil {
+		setNameFromClaims(client, claims)
+	}
+	return client, nil
+}
+
+// getRemoteHost is the address a request came from without its port
+func getRemoteHost(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// DraftCookieLifetime is how long the draft cookie holds a player's seat
+const DraftCookieLifetime = 30 * time.Minute
+
+// getDraftCookieLifetime keeps a correspondence drafter's cookie until every
+// pick left could have run to its deadline, they may be gone for hours at a
+// time. It runs on the Listen loop.
+func (director *GameDirector) getDraftCookieLifetime() time.Duration {
+	if !director.isCorrespondence() {
+		return DraftCookieLifetime
+	}
+	director.pickMu.Lock()
+	picksLeft := 0
+	for packNumber, round := range director.roundPacks {
+		if packNumber < director.packNumber {
+			continue
+		}
+		largest := 0
+		for _, pack := range round.PlayerPacks {
+			if len(pack) > largest {
+				largest = len(pack)
+			}
+		}
+		picksLeft += largest
+	}
+	director.pickMu.Unlock()
+	return DraftCookieLifetime + time.Duration(picksLeft)*director.getPickDuration(1)
+}
+
+func (director *GameDirector) getUpgrader() *websocket.Upgrader {
+	return &websocket.Upgrader{
 		ReadBufferSize:  1024,
 		WriteBufferSize: 1024,
-		CheckOrigin: func(r *http.Request) bool {
-			return true
-		},
+		CheckOrigin:     director.checkOrigin,
 	}
+}
 
-	ws, err := upgrader.Upgrade(w, r, DraftClientIDCookieHeader)
+// reattachClient hands a returning client's seat, pool and current pack back to it
+// on a new websocket, any connection it still has open is closed.
+func (director *GameDirector) reattachClient(client *Client, w http.ResponseWriter, r *http.Request, cookieLifetime time.Duration) {
+	DraftClientIDCookieHeader := utils.CreateDraftClientIDCookieHeader(client.seatToken, models.DraftCookieName, director.basePath, cookieLifetime)
+
+	ws, err := director.getUpgrader().Upgrade(w, r, DraftClientIDCookieHeader)
 	if err != nil {
 		director.Error(err)
 		_, _ = fmt.Fprintf(w, err.Error())
+		return
 	}
 
-	newClient.Websocket = ws
+	previousConn := client.currentConn()
+	client.Reconnect(ws)
+	previousConn.close()
+	director.ReconnectClient(client)
+	go client.Listen()
+}
+
+func (director *GameDirector) resumeClient(c *Client) {
+	msgs := append([]*models.Message{}, director.messages...)
 
-	if director.host == models.NoHostSentinel {
-		director.host = newClient.Id
-		newClient.Write(&models.Message{
-			Type: models.HostChange,
-			Data: strconv.Itoa(1),
-		})
+	if host, ok := director.Clients[director.host]; !ok || !host.IsConnected() {
+		director.host = c.Id
 	}
-	director.AddNewClient(newClient)
-	go newClient.Listen()
+	if director.host == c.Id {
+		msgs = append(msgs, models.NewMessage(models.HostChange, 1))
+	}
+
+	if seat, ok := director.Seats[c.Id]; ok && director.phase == models.PhaseDrafting {
+		director.pickMu.Lock()
+		if director.getHeadPack(seat) != nil {
+			msgs = append(msgs, director.getRoundContentMessage(seat))
+		}
+		msgs = append(msgs, director.getPickStatusMessage())
+		director.pickMu.Unlock()
+	}
+	msgs = append(msgs, c.getPoolMessage())
+
+	if _, ok := director.Seats[c.Id]; ok && director.phase == models.PhaseDeckbuilding {
+		msgs = append(msgs, c.getDeckMessage())
+		if director.isDeckbuildingTimerEnabled() {
+			msgs = append(msgs, director.getDeckbuildingTimerMessage())
+		}
+	}
+
+	go func() {
+		for _, msg := range msgs {
+			c.Write(msg)
+		}
+	}()
+}
+
+func (director *GameDirector) getConnectedClientCount() int {
+	connected := 0
+	for _, c := range director.Clients {
+		if c.IsConnected() {
+			connected++
+		}
+	}
+	return connected
 }
 
 func (director *GameDirector) isExistingClient(clientId string) bool {
@@ -165,36 +484,116 @@ func (director *GameDirector) isExistingClient(clientId string) bool {
 }
 
 func (director *GameDirector) HandleClientMessage(clientID string, msg *models.Message) {
-	logger := internal.GetLogger()
+	payload, ok := director.decodeClientMessage(clientID, msg)
+	if !ok {
+		return
+	}
+	if msg.Type == models.Hello {
+		director.handleClientHello(clientID, payload.(*models.HelloJson))
+		return
+	}
+	if director.isSpectator(clientID) {
+		// spectators only watch
+		return
+	}
+
+	var err error
+	code := models.ErrRejected
 	switch msg.Type {
 	case models.ChatMessage:
-		director.SendAll(msg)
+		err = director.handleClientChat(clientID, payload.(*models.ChatMessageJson))
 		break
 	case models.GameStart:
+		if clientID != director.host {
+			err, code = errors.New("only the host can start the game"), models.ErrUnauthorized
+			break
+		}
 		if !director.gameStarted {
-			var timerSetting = &models.TimerSettings{}
-			if err := json.Unmarshal([]byte(msg.Data), &timerSetting); err != nil {
-				director.Error(err)
-			} else {
-				director.roundTimerType = timerSetting.Type
-			}
-			logger.Infow("Starting Game!")
-			director.SendAll(msg)
-			go director.startGame()
+			timerSetting := payload.(*models.TimerSettings)
+			director.roundTimerType = timerSetting.Type
+			director.beginGame(timerSetting)
+		}
+		break
+	case models.SetName:
+		err = director.handleClientSetName(clientID, payload.(*models.SetNameJson))
+		break
+	case models.SetReady:
+		if director.phase != models.PhaseLobby {
+			err, code = errors.New("players can only ready up in the lobby"), models.ErrWrongPhase
+			break
+		}
+		err = director.handleClientSetReady(clientID, payload.(*models.SetReadyJson))
+		break
+	case models.RandomizeSeats, models.ArrangeSeats:
+		if clientID != director.host {
+			err, code = errors.New(fmt.Sprintf("only the host can %s", msg.Type)), models.ErrUnauthorized
+			break
+		}
+		if director.phase != models.PhaseLobby {
+			err, code = errors.New("seats can only change in the lobby"), models.ErrWrongPhase
+			break
+		}
+		if msg.Type == models.RandomizeSeats {
+			director.handleHostRandomizeSeats()
+		} else {
+			err = director.handleHostArrangeSeats(payload.(*models.ArrangeSeatsJson))
 		}
 		break
 	case models.ChooseCard:
-		if director.gameStarted {
-			if err := director.handleClientChooseCard(clientID, msg); err != nil {
-				director.Error(err)
-			} else {
-				director.roundPicksTickerCh <- 1
+		if director.phase != models.PhaseDrafting {
+			err, code = errors.New("the draft is not running"), models.ErrWrongPhase
+			break
+		}
+		if err = director.handleClientChooseCard(clientID, payload.(*models.ChooseCardJson), false); err == nil {
+			director.pickCardsForBots()
+			director.savePicks()
+		}
+		break
+	case models.MoveCard, models.SetBasicLands, models.DeckReady, models.DeckbuildingTimer:
+		if director.phase != models.PhaseDeckbuilding {
+			err, code = errors.New(fmt.Sprintf("%s only applies while deckbuilding", msg.Type)), models.ErrWrongPhase
+			break
+		}
+		switch msg.Type {
+		case models.MoveCard:
+			err = director.handleClientMoveCard(clientID, payload.(*models.MoveCardJson))
+		case models.SetBasicLands:
+			err = director.handleClientSetBasicLands(clientID, *payload.(*map[string]int))
+		case models.DeckReady:
+			err = director.handleClientDeckReady(clientID, payload.(*models.DeckReadyJson))
+		case models.DeckbuildingTimer:
+			if clientID != director.host {
+				err, code = errors.New("only the host can set the deckbuilding timer"), models.ErrUnauthorized
+				break
 			}
+			err = director.handleHostDeckbuildingTimer(payload.(*models.DeckbuildingTimerJson))
 		}
 		break
+	case models.KickPlayer, models.PauseTimer, models.ResumeTimer, models.ExtendRound, models.ForceAdvance, models.EndGameEarly,
+		models.MutePlayer, models.RetractChat:
+		if clientID != director.host {
+			err, code = errors.New(fmt.Sprintf("only the host can %s", msg.Type)), models.ErrUnauthorized
+			break
+		}
+		err = director.handleHostCommand(clientID, msg.Type, payload)
+		break
+	case models.MatchResult:
+		if director.phase != models.PhaseTournament {
+			err, code = errors.New("there is no tournament running"), models.ErrWrongPhase
+			break
+		}
+		err = director.handleClientMatchResult(clientID, payload.(*models.MatchResultJson))
+		break
 	default:
 		break
 	}
+
+	if err != nil {
+		if clientErr, ok := err.(*clientError); ok {
+			code = clientErr.code
+		}
+		director.writeError(clientID, msg.Type, code, err)
+	}
 }
 
 func (director *GameDirector) getSeatByClientId(clientId string) int {
@@ -210,53 +609,35 @@ func (director *GameDirector) getClientIdBySeat(target int) string {
 	return ""
 }
 
+// getPackByClientID is the pack at the head of the drafter's queue, callers hold pickMu
 func (director *GameDirector) getPackByClientID(clientId string) []models.SetCard {
-	playerSeat := director.getSeatByClientId(clientId)
-	return director.roundPacks[director.packNumber].PlayerPacks[playerSeat]
+	if head := director.getHeadPack(director.getSeatByClientId(clientId)); head != nil {
+		return head.Cards
+	}
+	return nil
 }
 
-func (director *GameDirector) handleClientChooseCard(clientID string, msg *models.Message) error {
-	rawMsgContents := msg.Data
-	client := director.Clients[clientID]
-	if client == nil {
-		return errors.New(fmt.Sprintf("No client with id: %s. Must provide valid client ID", clientID))
-	} else {
-
-		var selectedCardMsg models.ChooseCardJson
-		if err := json.Unmarshal([]byte(rawMsgContents), &selectedCardMsg); err != nil {
-			director.Error(err)
-		}
-
-		currentPack := director.getPackByClientID(client.Id)
-		if currentPack == nil {
-			return errors.New(fmt.Sprintf("client %s already chose this round, resent chose_card msg", client.Id))
-		}
-
-		if selectedCardMsg.PickedCardIndex >= len(currentPack) || selectedCardMsg.PickedCardIndex < 0 {
-			return errors.New(fmt.Sprintf("[client %s] chose an invalid card index %d", clientID, selectedCardMsg.PickedCardIndex))
-		}
-
-		chosenCard := currentPack[selectedCardMsg.PickedCardIndex]
-
-		currentPack = append(currentPack[:selectedCardMsg.PickedCardIndex], currentPack[selectedCardMsg.PickedCardIndex+1:]...)
+// drafter is anything sitting in a seat, a player's Client or a Bot
+type drafter interface {
+	AddCardToPool(card models.SetCard)
+	WriteCurrentPool()
+}
 
-		playerSeat := director.getSeatByClientId(client.Id)
-		nextClientSeat := director.getSeatNumberForNextRound(playerSeat)
-		director.nextRoundPacks[nextClientSeat] = currentPack
-		director.roundPacks[director.packNumber].PlayerPacks[playerSeat] = nil
-		client.AddCardToPool(chosenCard)
-		client.WriteCurrentPool()
+func (director *GameDirector) getDrafter(id string) drafter {
+	if client, ok := director.Clients[id]; ok {
+		return client
+	}
+	if bot, ok := director.Bots[id]; ok {
+		return bot
 	}
 	return nil
 }
 
-func (director *GameDirector) haveAllClientsPickedCurrentRound() bool {
-	for _, pp := range director.roundPacks[director.packNumber].PlayerPacks {
-		if pp != nil {
-			return false
-		}
-	}
-	return true
+// handleClientChooseCard takes a player's pick, see takePick
+func (director *GameDirector) handleClientChooseCard(clientID string, selectedCardMsg *models.ChooseCardJson, forced bool) error {
+	director.pickMu.Lock()
+	defer director.pickMu.Unlock()
+	return director.takePick(clientID, selectedCardMsg, forced)
 }
 
 func (director *GameDirector) getSeatNumberForNextRound(currentSeat int) int {
@@ -276,89 +657,139 @@ func (director *GameDirector) getSeatNumberForNextRound(currentSeat int) int {
 	}
 }
 
-func (director *GameDirector) pickCardsForStallingClients() {
-	for seatNum, pp := range director.roundPacks[director.packNumber].PlayerPacks {
-		if pp != nil {
+// seatClients seats players in the lobby's seating order, anyone who never
+// made it into the lobby order takes the seats left over
+func (director *GameDirector) seatClients(totalSeats int) {
+	order := director.getSeatOrder()
+	inOrder := make(map[string]bool)
+	for _, clientID := range order {
+		inOrder[clientID] = true
+	}
+	for clientID := range director.Clients {
+		if !inOrder[clientID] && !director.Clients[clientID].spectator {
+			order = append(order, clientID)
+		}
+	}
 
-			forcePick, err := json.Marshal(&models.ChooseCardJson{
-				PickedCardIndex: 0,
-			})
-			if err != nil {
-				director.Error(err)
-				director.shutdown()
-				break
-			}
+	var currentPlayer = 0
+	for _, clientID := range order {
+		if currentPlayer >= totalSeats {
+			break
+		}
+		if _, ok := director.Clients[clientID]; !ok {
+			continue
+		}
+		director.Seats[clientID] = currentPlayer
+		currentPlayer++
+	}
+}
 
-			clientId := director.getClientIdBySeat(seatNum)
-			err = director.handleClientChooseCard(clientId, &models.Message{
-				Type: models.ChooseCard,
-				Data: string(forcePick),
-			})
+// seatBots fills every seat left empty after seatClients with a bot
+func (director *GameDirector) seatBots(totalSeats int) {
+	logger := internal.GetLogger()
+	takenSeats := make(map[int]bool)
+	for _, seat := range director.Seats {
+		takenSeats[seat] = true
+	}
 
-			if err != nil {
-				director.Error(err)
-				director.shutdown()
-			}
+	for seat := 0; seat < totalSeats; seat++ {
+		if takenSeats[seat] {
+			continue
+		}
+		strategy, err := NewBotStrategy(director.options.BotStrategy, director.rng)
+		if err != nil {
+			director.Error(err)
+			strategy, _ = NewBotStrategy(DefaultBotStrategy, director.rng)
 		}
+		bot := NewBot(director, seat, strategy)
+		director.Bots[bot.Id] = bot
+		director.Seats[bot.Id] = seat
+		logger.Infow("Seated bot", "bot", bot.Id, "seat", seat)
 	}
 }
 
+func (director *GameDirector) dealFirstRound() {
+	CurrentRound := director.roundPacks[director.packNumber]
+	director.seatClients(len(CurrentRound.PlayerPacks))
+	director.seatBots(len(CurrentRound.PlayerPacks))
+	director.dealPack()
+}
+
+func (director *GameDirector) dealSealedPools() {
+	director.seatClients(len(director.sealedPools))
+	for clientID, seat := range director.Seats {
+		client := director.Clients[clientID]
+		for _, card := range director.sealedPools[seat] {
+			client.AddCardToPool(card)
+		}
+		client.WriteCurrentPool()
+	}
+}
+
+// beginGame starts the game once, whether the host started it or every
+// player readied up. It runs on the Listen loop.
+func (director *GameDirector) beginGame(timerSetting *models.TimerSettings) {
+	director.startOnce.Do(func() {
+		internal.GetLogger().Infow("Starting Game!", "game", director.GameId)
+		director.gameStarted = true
+		director.broadcast(models.NewMessage(models.GameStart, timerSetting))
+		director.startGame()
+	})
+}
+
 func (director *GameDirector) startGame() {
 	director.gameStarted = true
 	switch director.options.Type {
 	case game.DRAFT:
+		director.phase = models.PhaseDrafting
 		switch director.options.Mode {
 		case game.CHAOS:
+			director.dealFirstRound()
 			break
 		case game.CUBE:
+			director.dealFirstRound()
 			break
 		case game.REGULAR:
-			CurrentRound := director.roundPacks[director.packNumber]
-			var currentPlayer = 0
-			for clientID, client := range director.Clients {
-
-				if currentPlayer >= len(CurrentRound.PlayerPacks) {
-					break
-				}
-
-				playerPack := CurrentRound.PlayerPacks[currentPlayer]
-				director.Seats[clientID] = currentPlayer
-
-
-				newPack := &models.CardPack{
-					SetName:    CurrentRound.SetAbbreviation,
-					Pack:       playerPack,
-					Round:      director.round,
-					PackNumber: director.packNumber + 1,
-				}
-
-				if director.isTimerEnabled() {
-					newPack.Timer = int(director.getRoundTimer() / time.Second)
-				}
-
-
-				emp, _ := json.Marshal(newPack)
-
-				go client.Write(&models.Message{
-					Type: models.RoundContent,
-					Data: string(emp),
-				})
-
-				currentPlayer++
-			}
+			director.dealFirstRound()
 			break
 		default:
 			panic(fmt.Sprintf("Unknown game mode: %d", director.options.Mode))
 		}
-		director.roundPicksTickerCh = director.startRoundPicksTicker()
+		director.saveSnapshot()
+		director.startPickTicker()
+		director.pickCardsForBots()
 		break
 	case game.SEALED:
+		director.dealSealedPools()
+		director.startDeckbuilding()
+		director.saveSnapshot()
 		break
 	default:
 		panic(fmt.Sprintf("Unknown game type: %d", director.options.Type))
 	}
 }
 
+// resumeGame restarts the current round of a director restored from a
+// snapshot, players get their packs back as they reconnect. Listen runs it
+// before handling anything else.
+func (director *GameDirector) resumeGame() {
+	logger := internal.GetLogger()
+	logger.Infow("Resuming game", "game", director.GameId, "phase", director.phase, "pack_number", director.packNumber)
+	if director.phase == models.PhaseDrafting {
+		director.startPickTicker()
+		director.pickCardsForBots()
+		director.pickMu.Lock()
+		packOver := director.isPackOver()
+		director.pickMu.Unlock()
+		if packOver {
+			// the last pick of a pack went in just before the snapshot
+			go director.signalNextPack()
+		}
+	} else if director.phase == models.PhaseDeckbuilding {
+		director.startDeckbuildingTicker()
+	}
+}
+
 func (director *GameDirector) IsEndOfDraft() bool {
 	if _, ok := director.roundPacks[director.packNumber]; !ok {
 		return true
@@ -366,173 +797,145 @@ func (director *GameDirector) IsEndOfDraft() bool {
 	return false
 }
 
+// hasDraftFinished is true from deckbuilding onwards
+func (director *GameDirector) hasDraftFinished() bool {
+	switch director.phase {
+	case models.PhaseDeckbuilding, models.PhaseTournament, models.PhaseEnded:
+		return true
+	}
+	return false
+}
+
 func (director *GameDirector) startNextPack() {
+	director.pickMu.Lock()
 	director.packNumber += 1
+	director.pickMu.Unlock()
 	logger := internal.GetLogger()
 	logger.Infow("Starting next pack", "pack_number", director.packNumber)
 }
 
-func (director *GameDirector) startNextRound() {
-	CurrentPackRound := director.roundPacks[director.packNumber]
-	for clientID, i := range director.Seats {
-
-		if i >= len(CurrentPackRound.PlayerPacks) {
-			break
-		}
-		client := director.Clients[clientID]
-		playerPack := CurrentPackRound.PlayerPacks[i]
-
-		newPack := &models.CardPack{
-			SetName:    CurrentPackRound.SetAbbreviation,
-			Pack:       playerPack,
-			Round:      director.round,
-			PackNumber: director.packNumber + 1,
-		}
-
-		if director.isTimerEnabled() {
-			newPack.Timer = int(director.getRoundTimer() / time.Second)
-		}
-
-		emp, _ := json.Marshal(newPack)
-
-
-		client.Write(&models.Message{
-			Type: models.RoundContent,
-			Data: string(emp),
-		})
-
-	}
-	director.roundPicksTickerCh = director.startRoundPicksTicker()
-}
-
+// correspondence drafts always have a timer and always pick for drafters who
+// run out of time, nobody is around to pick by hand
 func (director *GameDirector) isTimerEnabled() bool {
-	return director.roundTimerType != ""
+	return director.roundTimerType != "" || director.isCorrespondence()
 }
 
 func (director *GameDirector) isServerForcePickEnabled() bool {
-	return director.roundTimerServerForcePick == true
+	return director.roundTimerServerForcePick == true || director.isCorrespondence()
 }
 
-func (director *GameDirector) getRoundTimer() time.Duration {
-	var roundTime = 1 * time.Second
-	switch director.roundTimerType {
-	case "leisurely":
-		//'Leisurely - Starts @ 90s and decrements by 5s per pick'
-		roundTime = 90*time.Second - (5 * time.Second * (time.Duration(director.round - 1)))
-		break
-	case "slow":
-		//'Slow - Starts @ 75s and decrements by 5s per pick'
-		roundTime = 75*time.Second - (5 * time.Second * (time.Duration(director.round - 1)))
-		break
-	case "moderate":
-		//'Moderate - Starts @ 55s A happy medium between slow, and fast.'
-		roundTime = 55*time.Second - (5 * time.Second * (time.Duration(director.round - 1)))
-		break
-	case "fast":
-		//'Fast - Starts @ 40s, based on official WOTC timing'
-		roundTime = 40*time.Second - (5 * time.Second * (time.Duration(director.round - 1)))
-		break
-	}
-
-	if roundTime < 3*time.Second {
-		roundTime = 3 * time.Second
-	}
-	return roundTime
+// finishAfterGracePeriod keeps the game up long enough after it ends for
+// players to download their pools, then stops its Listen loop.
+func (director *GameDirector) finishAfterGracePeriod() {
+	time.Sleep(director.endGracePeriod)
+	internal.GetLogger().Infow("Grace period over, shutting down.", "game", director.GameId)
+	director.finish(func() bool { return true }, true)
 }
 
-func (director *GameDirector) startRoundPicksTicker() chan int {
-
-	logger := internal.GetLogger()
-	var roundTime time.Duration
-	if director.isTimerEnabled() {
-		roundTime = director.getRoundTimer()
-	}
+// LobbyIdleTimeout is how long a lobby stays up with nobody connected
+const LobbyIdleTimeout = 10 * time.Minute
 
-	ticks := 0
-	ticker := time.NewTicker(1 * time.Second)
-	pickIncrease := make(chan int, len(director.Seats))
-	go func() {
-		var picks = 0
-		for {
-			select {
-			case <-ticker.C:
-				ticks += 1
-				if director.isTimerEnabled() && director.isServerForcePickEnabled() && time.Duration(ticks)*time.Second == roundTime {
-					logger.Infow("Times Up! Forcing autopicks and ending round", "round", director.round)
-					director.startNextRoundCh <- true
-					close(pickIncrease)
-					ticker.Stop()
-					return
-				} else if picks == len(director.Seats) {
-					logger.Infow("all players have picked, ending round", "round", director.round)
-					director.startNextRoundCh <- true
-					close(pickIncrease)
-					ticker.Stop()
-					return
-				}
-			case <-pickIncrease:
-				picks += 1
-			}
-		}
-	}()
+// AbandonedGameTimeout is how long a started game stays up with nobody
+// connected, long enough for a table to sit out a dropped connection
+const AbandonedGameTimeout = 30 * time.Minute
 
-	return pickIncrease
+// getIdleTimeout is how long the game may go without anyone connected,
+// correspondence drafts run with nobody around and are never idle
+func (director *GameDirector) getIdleTimeout() time.Duration {
+	if director.phase == models.PhaseLobby {
+		return director.idleTimeout
+	}
+	return director.abandonedTimeout
 }
 
-func (director *GameDirector) rotateCards() {
-	for key, pack := range director.nextRoundPacks {
-		director.roundPacks[director.packNumber].PlayerPacks[key] = pack
-	}
+func (director *GameDirector) isIdle() bool {
+	return director.phase != models.PhaseEnded && !director.isCorrespondence() && director.getConnectedClientCount() == 0
 }
 
-func (director *GameDirector) shouldStartNewPack() bool {
-	var totalEmptyPacks = 0
-	for _, pack := range director.nextRoundPacks {
-		if len(pack) == 0 {
-			totalEmptyPacks += 1
-		}
+// markIdle starts the idle clock once the last player leaves, the game is
+// removed if nobody comes back in time. It runs on the Listen loop.
+func (director *GameDirector) markIdle() {
+	if !director.isIdle() {
+		director.idleSince = time.Time{}
+		return
 	}
-	if totalEmptyPacks == len(director.Seats) {
-		return true
+	if !director.idleSince.IsZero() {
+		return
 	}
-	return false
+	director.idleSince = time.Now()
+	director.watchIdle(director.getIdleTimeout())
 }
 
-func (director *GameDirector) pause() {
-	logger := internal.GetLogger()
-	logger.Infow("NO HOST! *PAUSING*.")
-	ticks := 0
-	ticker := time.NewTicker(5 * time.Second)
-	for {
-		select {
-		case <-ticker.C:
-			ticks += 1
-			if director.host != models.NoHostSentinel {
-				logger.Infow("NEW HOST! *UNPAUSING*.")
-				ticker.Stop()
-				break
+// watchIdle checks back after wait, a game that is still idle but moved on
+// to a longer timeout meanwhile is checked again once that is up
+func (director *GameDirector) watchIdle(wait time.Duration) {
+	time.AfterFunc(wait, func() {
+		var timeout time.Duration
+		removed := director.finish(func() bool {
+			timeout = director.getIdleTimeout()
+			if !director.isIdle() || director.idleSince.IsZero() {
+				return false
 			}
-			if ticks == 6 {
-				logger.Infow("Shutting down server. No host after 30 second grace period.")
-				os.Exit(1)
+			if remaining := timeout - time.Since(director.idleSince); remaining > 0 {
+				director.watchIdle(remaining)
+				return false
 			}
+			if director.phase != models.PhaseLobby {
+				// players coming back later resume from the snapshot
+				director.saveSnapshot()
+			} else {
+				// whoever comes back gets a fresh lobby, not one left by an older build
+				director.removeSnapshot()
+			}
+			return true
+		}, false)
+		if removed {
+			internal.GetLogger().Infow("Removed idle game.", "game", director.GameId, "idle_timeout", timeout.String())
 		}
+	})
+}
+
+// finish stops the Listen loop if shouldFinish is true when the loop gets to
+// it, ended tells onFinished whether the game was played to its end. It
+// reports whether the game was finished.
+func (director *GameDirector) finish(shouldFinish func() bool, ended bool) bool {
+	var clients []*Client
+	finished := false
+	director.runOnLoop(func() {
+		if !shouldFinish() {
+			return
+		}
+		for _, c := range director.Clients {
+			clients = append(clients, c)
+		}
+		// closed on the loop so nobody joins between the check and the close
+		close(director.finishedCh)
+		finished = true
+	})
+	if !finished {
+		return false
 	}
+	for _, c := range clients {
+		c.Done()
+	}
+	if director.onFinished != nil {
+		director.onFinished(director, ended)
+	}
+	return true
 }
 
 func (director *GameDirector) promoteNewHost() {
 	var nextHostId string
-	for k := range director.Clients {
-		nextHostId = k
+	for k, c := range director.Clients {
+		if c.IsConnected() {
+			nextHostId = k
+		}
 	}
 	if nextHostId == "" {
 		director.host = models.NoHostSentinel
 	} else {
 		director.host = nextHostId
-		director.sendHostMessage(&models.Message{
-			Type: models.HostChange,
-			Data: strconv.Itoa(1),
-		})
+		director.sendHostMessage(models.NewMessage(models.HostChange, 1))
 	}
 }
 
@@ -543,42 +946,40 @@ func (director *GameDirector) getGameResources() error {
 		switch director.options.Mode {
 		case game.CHAOS:
 			opts := director.options.GameOptions.Draft.Chaos
+			sets, err := director.getCardSource().GetSets()
+			if err != nil {
+				return err
+			}
 
+			rounds, err := buildChaosRounds(director.getCardSource(), getChaosSets(sets, opts.OnlyModern), director.options.TotalPlayers, opts.TotalPacks, opts.TotalChaos, director.rng)
+			if err != nil {
+				return err
+			}
+			director.roundPacks = rounds
 			director.totalPacks = opts.TotalPacks
 			break
 		case game.CUBE:
 			opts := director.options.GameOptions.Draft.Cube
+			cube, err := ParseCubeList(opts.CubeList)
+			if err != nil {
+				return err
+			}
 
+			rounds, err := buildCubeRounds(cube, director.options.TotalPlayers, opts.TotalPacks, opts.CardsPerPack, director.rng)
+			if err != nil {
+				return err
+			}
+			director.roundPacks = rounds
 			director.totalPacks = opts.TotalPacks
 			break
 		case game.REGULAR:
 			opts := director.options.GameOptions.Draft.Regular
-			for i := 0; i < opts.TotalPacks; i++ {
-				setAbbrev := opts.SelectedPacks[strconv.Itoa(i)]
-				res, err := http.Get(fmt.Sprintf("%s/set/%s/pack?n=%d", ApiUri, setAbbrev, director.options.TotalPlayers))
-				if err != nil {
-					logger.Fatalw("cannot get game options", "error", err.Error())
-				}
-				var boosters models.SetPacks
-				msg, err := ioutil.ReadAll(res.Body)
-				if err != nil {
-					panic(err)
-				}
-
-				if err := json.Unmarshal(msg, &boosters); err != nil {
-					logger.Fatalw("cannot decode booster json for packs", "set", setAbbrev)
-					panic(err)
-				}
-
-				playerPacks := make(map[int][]models.SetCard)
-				for i, packs := range boosters.Packs {
-					playerPacks[i] = packs
-				}
-				director.roundPacks[i] = models.DraftRound{
-					SetAbbreviation: setAbbrev,
-					PlayerPacks:     playerPacks,
-				}
+			rounds, err := fetchRegularRounds(director.getCardSource(), opts.SelectedPacks, opts.TotalPacks, director.options.TotalPlayers)
+			if err != nil {
+				logger.Errorw("cannot get boosters", "error", err.Error())
+				return err
 			}
+			director.roundPacks = rounds
 			director.totalPacks = opts.TotalPacks
 			break
 		default:
@@ -589,19 +990,45 @@ func (director *GameDirector) getGameResources() error {
 		switch director.options.Mode {
 		case game.CHAOS:
 			opts := director.options.GameOptions.Sealed.Chaos
+			sets, err := director.getCardSource().GetSets()
+			if err != nil {
+				return err
+			}
 
+			rounds, err := buildChaosRounds(director.getCardSource(), getChaosSets(sets, opts.OnlyModern), director.options.TotalPlayers, opts.TotalPacks, opts.TotalChaos, director.rng)
+			if err != nil {
+				return err
+			}
+			director.sealedPools = getSealedPools(rounds)
 			director.totalPacks = opts.TotalPacks
 			break
 		case game.CUBE:
-			//opts := director.options.GameOptions.Sealed.Cube
+			opts := director.options.GameOptions.Sealed.Cube
+			cube, err := ParseCubeList(opts.CubeList)
+			if err != nil {
+				return err
+			}
 
+			// a cube sealed pool is one big pack per player
+			rounds, err := buildCubeRounds(cube, director.options.TotalPlayers, 1, opts.CardsPerPlayer, director.rng)
+			if err != nil {
+				return err
+			}
+			director.sealedPools = getSealedPools(rounds)
 			director.totalPacks = 1
 			break
 		case game.REGULAR:
 			opts := director.options.GameOptions.Sealed.Regular
-
+			rounds, err := fetchRegularRounds(director.getCardSource(), opts.SelectedPacks, opts.TotalPacks, director.options.TotalPlayers)
+			if err != nil {
+				logger.Errorw("cannot get boosters", "error", err.Error())
+				return err
+			}
+			director.sealedPools = getSealedPools(rounds)
 			director.totalPacks = opts.TotalPacks
 			break
+		default:
+			return errors.New(fmt.Sprintf("Unknown game mode: %d", director.options.Mode))
 		}
 		break
 	default:
@@ -613,89 +1040,110 @@ func (director *GameDirector) getGameResources() error {
 func (director *GameDirector) Listen() {
 	logger := internal.GetLogger()
 	logger.Infow("Listening", "game", director.GameId, "port", director.Port)
-	// upgrade this connection to a WebSocket
-
-	http.HandleFunc("/ws", director.newClient)
-	logger.Infow("Created /ws handler")
+	if director.resuming {
+		director.resuming = false
+		director.resumeGame()
+	}
+	// nobody may ever manage to join
+	director.markIdle()
 
 	for {
 		select {
+		case <-director.finishedCh:
+			logger.Infow("Stopped listening", "game", director.GameId)
+			return
 		case c := <-director.addClientCh:
+			if c.spectator {
+				director.addSpectator(c)
+				break
+			}
 			logger.Debugw("Added new client")
 			director.Clients[c.Id] = c
+			director.markIdle()
+			delete(director.joining, c.Id)
+			director.inheritChatLimits(c)
+			if c.seatToken != "" {
+				director.seatTokens[c.seatToken] = c.Id
+			}
+			if director.host == models.NoHostSentinel {
+				director.host = c.Id
+				c.Write(models.NewMessage(models.HostChange, 1))
+			}
+			if !director.gameStarted {
+				director.addToSeatOrder(c.Id)
+			}
 			logger.Debugw("Total", "clients", len(director.Clients))
-			go director.sendAll(&models.Message{
-				Type: models.NewPlayer,
-				Data: strconv.Itoa(len(director.Clients)),
-			})
-			go director.sendPastMessages(c)
+			director.sendAll(models.NewMessage(models.NewPlayer, director.getConnectedClientCount()))
+			director.sendPastMessages(c)
+			director.sendAll(director.getRosterMessage())
+		case c := <-director.reconnectClientCh:
+			logger.Debugw("Reconnected client", "client", c.Id)
+			if _, ok := director.Clients[c.Id]; !ok {
+				// its old connection dropped it from the lobby while it was reattaching
+				director.Clients[c.Id] = c
+				if !director.gameStarted {
+					director.addToSeatOrder(c.Id)
+				}
+			}
+			director.resumeClient(c)
+			director.markIdle()
+			director.sendAll(director.getRosterMessage())
 		case c := <-director.delClientCh:
 			clientID := c.Id
-			logger.Debugw("Removing client", "client", clientID)
-			delete(director.Clients, clientID)
+			if c.spectator {
+				director.removeSpectator(c)
+				break
+			}
+			if c.IsConnected() {
+				// already came back on a new connection
+				break
+			}
+
+			if _, seated := director.Seats[clientID]; seated {
+				logger.Debugw("Seated client disconnected, holding seat", "client", clientID)
+			} else {
+				logger.Debugw("Removing client", "client", clientID)
+				delete(director.Clients, clientID)
+				director.removeFromSeatOrder(clientID)
+			}
 
 			if clientID == director.host {
 				director.promoteNewHost()
 			}
-			go director.SendAll(&models.Message{
-				Type: models.NewPlayer,
-				Data: strconv.Itoa(len(director.Clients)),
-			})
+			director.markIdle()
+			director.broadcast(models.NewMessage(models.NewPlayer, director.getConnectedClientCount()))
+			director.sendAll(director.getRosterMessage())
 		case msg := <-director.sendAllCh:
-			if msg.Type != models.RoundContent {
-				logger.Debugw("Sending to all clients", "msg", msg)
-			}
-			director.messages = append(director.messages, msg)
-			director.sendAll(msg)
-		case <-director.startNextRoundCh:
-			if director.shouldStartNewPack() {
-				director.startNextPack()
-				director.round = 1
-			} else {
-				if !director.haveAllClientsPickedCurrentRound() {
-						director.pickCardsForStallingClients()
-				}
-				director.round += 1
-				director.rotateCards()
-			}
+			director.broadcast(msg)
+		case <-director.startNextPackCh:
+			director.startNextPack()
 			if director.IsEndOfDraft() {
-				logger.Infow("shutting down")
-				go director.shutdown()
+				logger.Infow("draft over")
+				director.startDeckbuilding()
 			} else {
-				director.startNextRound()
+				director.dealPack()
+				director.saveSnapshot()
+				director.pickCardsForBots()
 			}
-		case err := <-director.errCh:
-			logger.Errorw("error occurred", "error", err.Error())
+		case fn := <-director.loopCh:
+			fn()
+		case reply := <-director.statusCh:
+			reply <- director.getStatus()
 		case <-director.doneCh:
-			director.sendAll(&models.Message{
-				Type: models.GameEnd,
-				Data: strconv.Itoa(len(director.Clients)),
-			})
-			for _, c := range director.Clients {
-				c.Done()
+			if director.phase == models.PhaseEnded {
+				// already winding down, the grace period is running
+				break
 			}
-			logger.Infow("Ended Game.", "game", director.GameId)
-			os.Exit(0)
+			director.phase = models.PhaseEnded
+			director.sendAll(models.NewMessage(models.GameEnd, len(director.Clients)))
+			director.removeSnapshot()
+			logger.Infow("Ended Game.", "game", director.GameId, "grace_period", director.endGracePeriod.String())
+			go director.finishAfterGracePeriod()
 		}
 	}
 
 }
 
-func getGeneralGameOptions(Url string) (game.GeneralOptions, error) {
-	var gameOptions game.GeneralOptions
-	res, err := http.Get(Url)
-	defer res.Body.Close()
-	if err != nil {
-		return gameOptions, err
-	} else {
-		if err := json.NewDecoder(res.Body).Decode(&gameOptions); err != nil {
-			return gameOptions, err
-		} else {
-			return gameOptions, nil
-		}
-	}
-}
-
 func getAPIUrlFromEnv(envKey string) string {
 	if envKey == "" {
 		envKey = "NODE_ENV"
@@ -709,22 +1157,3 @@ func getAPIUrlFromEnv(envKey string) string {
 }
 
 var ApiUri string
-
-func StartDraftServer(gameId string, port int) {
-	ApiUri = getAPIUrlFromEnv("NODE_ENV")
-	gameOptions, err := getGeneralGameOptions(fmt.Sprintf("%s/game/%s", ApiUri, gameId))
-	if err != nil {
-		panic(err)
-	}
-
-	director := NewGameDirector(gameOptions, port, gameId)
-
-	if err := director.getGameResources(); err != nil {
-		panic(err)
-	}
-
-	go director.Listen()
-
-	http.Handle("/", http.FileServer(http.Dir("webroot")))
-	log.Fatal(http.ListenAndServe(fmt.Sprintf(":%d", port), nil))
-}
@@ -1,8 +1,15 @@
 package director_test
 
 import (
+	"encoding/json"
+	"fmt"
 	"github.com/malexanderboyd/pwr9-godr4ft/internal/director"
+	"github.com/malexanderboyd/pwr9-godr4ft/internal/director/models"
 	"github.com/malexanderboyd/pwr9-godr4ft/internal/game"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
 	"testing"
 )
 
@@ -12,7 +19,6 @@ func TestCreateNewGameDirector(t *testing.T) {
 	var port = 9000
 	var gameId = "a_test_game"
 
-
 	d := director.NewGameDirector(mockOptions, port, gameId)
 
 	if d.Port != port {
@@ -25,20 +31,55 @@ func TestCreateNewGameDirector(t *testing.T) {
 
 }
 
+func TestGameDirectorGetGameResources(t *testing.T) {
 
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/sets" {
+			_ = json.NewEncoder(w).Encode([]models.SetInfo{
+				{Code: "LEA", ReleaseDate: "1993-08-05", Type: "core"},
+				{Code: "M20", ReleaseDate: "2019-07-12", Type: "core"},
+				{Code: "ELD", ReleaseDate: "2019-10-04", Type: "expansion"},
+			})
+			return
+		}
+		n, _ := strconv.Atoi(r.URL.Query().Get("n"))
+		var boosters models.SetPacks
+		for i := 0; i < n; i++ {
+			boosters.Packs = append(boosters.Packs, []models.SetCard{{Name: "Grizzly Bears"}, {Name: "Shock"}})
+		}
+		_ = json.NewEncoder(w).Encode(boosters)
+	}))
+	defer api.Close()
+	director.ApiUri = api.URL
 
-func TestGameDirectorGetGameResources(t *testing.T) {
+	var cubeList strings.Builder
+	for i := 0; i < 12; i++ {
+		cubeList.WriteString(fmt.Sprintf("Cube Card %d\n", i))
+	}
 
 	var baseGeneralOptions = game.GeneralOptions{
 		TotalPlayers: 2,
-		PrivateGame: true,
-		GameTitle: "test game",
-		Mode: nil,
-		Type: nil,
-		GameOptions: nil,
+		PrivateGame:  true,
+		GameTitle:    "test game",
+		GameOptions: game.ModeMap{
+			Draft: game.DraftOptions{
+				Regular: game.DraftRegularOptions{
+					TotalPacks:    3,
+					SelectedPacks: map[string]string{"0": "M20", "1": "M20", "2": "M20"},
+				},
+				Cube: game.DraftCubeOptions{
+					CardsPerPack: 2,
+					TotalPacks:   3,
+					CubeList:     cubeList.String(),
+				},
+				Chaos: game.DraftChaosOptions{
+					TotalPacks: 3,
+					OnlyModern: true,
+				},
+			},
+		},
 	}
 
-
 	var resourcestests = []struct {
 		Type    game.Type
 		Mode    game.Mode
@@ -48,4 +89,269 @@ func TestGameDirectorGetGameResources(t *testing.T) {
 		{game.DRAFT, game.CUBE, baseGeneralOptions},
 		{game.DRAFT, game.CHAOS, baseGeneralOptions},
 	}
+
+	for _, tt := range resourcestests {
+		options := tt.options
+		options.Type = tt.Type
+		options.Mode = tt.Mode
+
+		d := director.NewGameDirector(options, 9000, "a_test_game")
+		if err := d.GetGameResources(); err != nil {
+			t.Fatalf("type=%d mode=%d: unexpected error %v", tt.Type, tt.Mode, err)
+		}
+
+		rounds := d.RoundPacks()
+		if len(rounds) != 3 {
+			t.Errorf("type=%d mode=%d: expected 3 pack rounds, got %d", tt.Type, tt.Mode, len(rounds))
+		}
+		for packNumber, round := range rounds {
+			if len(round.PlayerPacks) != options.TotalPlayers {
+				t.Errorf("type=%d mode=%d: pack %d has %d player packs, expected %d", tt.Type, tt.Mode, packNumber, len(round.PlayerPacks), options.TotalPlayers)
+			}
+			for seat, pack := range round.PlayerPacks {
+				if len(pack) != 2 {
+					t.Errorf("type=%d mode=%d: pack %d seat %d has %d cards, expected 2", tt.Type, tt.Mode, packNumber, seat, len(pack))
+				}
+			}
+		}
+	}
+}
+
+func TestGameDirectorGetGameResourcesChaosSetsPerPack(t *testing.T) {
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/sets" {
+			_ = json.NewEncoder(w).Encode([]models.SetInfo{
+				{Code: "LEA", ReleaseDate: "1993-08-05", Type: "core"},
+				{Code: "M20", ReleaseDate: "2019-07-12", Type: "core"},
+				{Code: "ELD", ReleaseDate: "2019-10-04", Type: "expansion"},
+				{Code: "PLST", ReleaseDate: "2020-01-01", Type: "promo"},
+			})
+			return
+		}
+		setAbbrev := strings.Split(r.URL.Path, "/")[2]
+		n, _ := strconv.Atoi(r.URL.Query().Get("n"))
+		var boosters models.SetPacks
+		for i := 0; i < n; i++ {
+			boosters.Packs = append(boosters.Packs, []models.SetCard{{Name: setAbbrev + " card"}})
+		}
+		_ = json.NewEncoder(w).Encode(boosters)
+	}))
+	defer api.Close()
+	director.ApiUri = api.URL
+
+	options := game.GeneralOptions{
+		TotalPlayers: 8,
+		Type:         game.DRAFT,
+		Mode:         game.CHAOS,
+	}
+	options.GameOptions.Draft.Chaos = game.DraftChaosOptions{
+		TotalPacks: 3,
+		OnlyModern: true,
+	}
+
+	d := director.NewGameDirector(options, 9000, "a_test_game")
+	if err := d.GetGameResources(); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	for packNumber, round := range d.RoundPacks() {
+		for seat, pack := range round.PlayerPacks {
+			setName := round.GetPackSetName(seat)
+			if setName != "M20" && setName != "ELD" {
+				t.Errorf("pack %d seat %d opened %s, expected a modern draftable set", packNumber, seat, setName)
+			}
+			if pack[0].Name != setName+" card" {
+				t.Errorf("pack %d seat %d is tracked as %s but holds %q", packNumber, seat, setName, pack[0].Name)
+			}
+		}
+	}
+}
+
+func TestGameDirectorGetGameResourcesTotalChaos(t *testing.T) {
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/sets" {
+			_ = json.NewEncoder(w).Encode([]models.SetInfo{
+				{Code: "LEA", ReleaseDate: "1993-08-05", Type: "core"},
+				{Code: "M19", ReleaseDate: "2018-07-13", Type: "core"},
+				{Code: "M20", ReleaseDate: "2019-07-12", Type: "core"},
+				{Code: "ELD", ReleaseDate: "2019-10-04", Type: "expansion"},
+				{Code: "THB", ReleaseDate: "2020-01-24", Type: "expansion"},
+				{Code: "IKO", ReleaseDate: "2020-04-24", Type: "expansion"},
+			})
+			return
+		}
+		setAbbrev := strings.Split(r.URL.Path, "/")[2]
+		n, _ := strconv.Atoi(r.URL.Query().Get("n"))
+		var boosters models.SetPacks
+		for i := 0; i < n; i++ {
+			var pack []models.SetCard
+			for j := 0; j < 15; j++ {
+				pack = append(pack, models.SetCard{Name: setAbbrev + " card"})
+			}
+			boosters.Packs = append(boosters.Packs, pack)
+		}
+		_ = json.NewEncoder(w).Encode(boosters)
+	}))
+	defer api.Close()
+	director.ApiUri = api.URL
+
+	options := game.GeneralOptions{
+		TotalPlayers: 1,
+		Type:         game.DRAFT,
+		Mode:         game.CHAOS,
+	}
+	options.GameOptions.Draft.Chaos = game.DraftChaosOptions{
+		TotalPacks: 1,
+		OnlyModern: true,
+		TotalChaos: true,
+	}
+
+	d := director.NewGameDirector(options, 9000, "a_test_game")
+	if err := d.GetGameResources(); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	pack := d.RoundPacks()[0].PlayerPacks[0]
+	if len(pack) != 15 {
+		t.Fatalf("expected a booster sized pack, got %d cards", len(pack))
+	}
+	setsSeen := make(map[string]bool)
+	for _, card := range pack {
+		if card.SetCode == "LEA" || card.Name != card.SetCode+" card" {
+			t.Errorf("card %q from %s is not from a modern draftable set", card.Name, card.SetCode)
+		}
+		setsSeen[card.SetCode] = true
+	}
+	// a lone pack can only mix sets if cards come from the whole pool
+	if len(setsSeen) < 2 {
+		t.Errorf("expected a total chaos pack to mix sets, got %v", setsSeen)
+	}
+}
+
+func TestGameDirectorGetGameResourcesSealed(t *testing.T) {
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n, _ := strconv.Atoi(r.URL.Query().Get("n"))
+		var boosters models.SetPacks
+		for i := 0; i < n; i++ {
+			boosters.Packs = append(boosters.Packs, []models.SetCard{{Name: "Grizzly Bears"}, {Name: "Shock"}, {Name: "Opt"}})
+		}
+		_ = json.NewEncoder(w).Encode(boosters)
+	}))
+	defer api.Close()
+	director.ApiUri = api.URL
+
+	var cubeList strings.Builder
+	for i := 0; i < 90; i++ {
+		cubeList.WriteString(fmt.Sprintf("Cube Card %d\n", i))
+	}
+
+	options := game.GeneralOptions{
+		TotalPlayers: 2,
+		Type:         game.SEALED,
+	}
+	options.GameOptions.Sealed.Regular = game.SealedRegularOptions{
+		TotalPacks:    6,
+		SelectedPacks: map[string]string{"0": "M20", "1": "M20", "2": "M20", "3": "M20", "4": "M20", "5": "M20"},
+	}
+	options.GameOptions.Sealed.Cube = game.SealedCubeOptions{
+		CardsPerPlayer: 45,
+		CubeList:       cubeList.String(),
+	}
+
+	var sealedtests = []struct {
+		Mode     game.Mode
+		poolSize int
+	}{
+		{game.REGULAR, 18},
+		{game.CUBE, 45},
+	}
+
+	for _, tt := range sealedtests {
+		options.Mode = tt.Mode
+		d := director.NewGameDirector(options, 9000, "a_test_game")
+		if err := d.GetGameResources(); err != nil {
+			t.Fatalf("mode=%d: unexpected error %v", tt.Mode, err)
+		}
+
+		pools := d.SealedPools()
+		if len(pools) != options.TotalPlayers {
+			t.Errorf("mode=%d: expected %d pools, got %d", tt.Mode, options.TotalPlayers, len(pools))
+		}
+		for seat, pool := range pools {
+			if len(pool) != tt.poolSize {
+				t.Errorf("mode=%d: seat %d pool has %d cards, expected %d", tt.Mode, seat, len(pool), tt.poolSize)
+			}
+		}
+	}
+}
+
+func TestGameDirectorGetGameResourcesCubeTooSmall(t *testing.T) {
+	options := game.GeneralOptions{
+		TotalPlayers: 8,
+		Type:         game.DRAFT,
+		Mode:         game.CUBE,
+	}
+	options.GameOptions.Draft.Cube = game.DraftCubeOptions{
+		CardsPerPack: 15,
+		TotalPacks:   3,
+		CubeList:     "Black Lotus\nAncestral Recall\n",
+	}
+
+	d := director.NewGameDirector(options, 9000, "a_test_game")
+	if err := d.GetGameResources(); err == nil {
+		t.Errorf("expected an error for a cube smaller than the table needs")
+	}
+}
+
+func TestHTTPCardSourceGetSetsErrorStatus(t *testing.T) {
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "<html>upstream down</html>", http.StatusBadGateway)
+	}))
+	defer api.Close()
+
+	_, err := director.NewHTTPCardSource(api.URL).GetSets()
+	if err == nil || !strings.Contains(err.Error(), "502") {
+		t.Errorf("expected the set list's status in the error, got %v", err)
+	}
+}
+
+func TestParseCubeList(t *testing.T) {
+	cards, err := director.ParseCubeList(`# power
+Black Lotus
+2 Lightning Bolt (M11)
+1x Counterspell [ema] 43
+1996 World Champion
+2x 1996 World Champion
+
+// removed
+`)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	if len(cards) != 7 {
+		t.Fatalf("expected 7 cards, got %d", len(cards))
+	}
+
+	if cards[0].Name != "Black Lotus" || len(cards[0].Printings) != 0 {
+		t.Errorf("unexpected card %q %v", cards[0].Name, cards[0].Printings)
+	}
+
+	if cards[1].Name != "Lightning Bolt" || cards[2].Name != "Lightning Bolt" || cards[1].Printings[0] != "M11" {
+		t.Errorf("expected two M11 Lightning Bolts, got %q and %q", cards[1].Name, cards[2].Name)
+	}
+
+	if cards[1].UUID == cards[2].UUID {
+		t.Errorf("duplicate cube cards must have distinct ids, both are %s", cards[1].UUID)
+	}
+
+	if cards[3].Name != "Counterspell" || cards[3].Printings[0] != "EMA" || cards[3].Number != "43" {
+		t.Errorf("unexpected card %q %v %q", cards[3].Name, cards[3].Printings, cards[3].Number)
+	}
+
+	for _, card := range cards[4:] {
+		if card.Name != "1996 World Champion" {
+			t.Errorf("a name starting with digits is not a count, got %q", card.Name)
+		}
+	}
 }
@@ -0,0 +1,200 @@
+package director
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"github.com/malexanderboyd/pwr9-godr4ft/internal/director/models"
+	"github.com/malexanderboyd/pwr9-godr4ft/internal/director/utils"
+	"net/http"
+	"strings"
+)
+
+const (
+	MtgoExportFormat  = "dek"
+	ArenaExportFormat = "arena"
+	TextExportFormat  = "txt"
+)
+
+// sets Arena knows by a different code than paper
+var arenaSetCodes = map[string]string{
+	"DOM": "DAR",
+	"CON": "CONF",
+}
+
+type deckLine struct {
+	card     models.SetCard
+	quantity int
+}
+
+// groupDeckLines counts copies of each card, keeping the order cards were
+// first seen in. Cards only group when key matches.
+func groupDeckLines(cards []models.SetCard, key func(card models.SetCard) string) []*deckLine {
+	var lines []*deckLine
+	byKey := make(map[string]*deckLine)
+	for _, card := range cards {
+		k := key(card)
+		if line, ok := byKey[k]; ok {
+			line.quantity++
+			continue
+		}
+		line := &deckLine{card: card, quantity: 1}
+		byKey[k] = line
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+func getCardSetCode(card models.SetCard) string {
+	if card.SetCode != "" {
+		return card.SetCode
+	}
+	if len(card.Printings) > 0 {
+		return card.Printings[0]
+	}
+	return ""
+}
+
+type mtgoDeck struct {
+	XMLName              xml.Name       `xml:"Deck"`
+	Xsd                  string         `xml:"xmlns:xsd,attr"`
+	Xsi                  string         `xml:"xmlns:xsi,attr"`
+	NetDeckID            int            `xml:"NetDeckID"`
+	PreconstructedDeckID int            `xml:"PreconstructedDeckID"`
+	Cards                []mtgoDeckCard `xml:"Cards"`
+}
+
+type mtgoDeckCard struct {
+	CatID     int    `xml:"CatID,attr,omitempty"`
+	Quantity  int    `xml:"Quantity,attr"`
+	Sideboard bool   `xml:"Sideboard,attr"`
+	Name      string `xml:"Name,attr"`
+}
+
+func exportMtgo(main, sideboard []models.SetCard) ([]byte, error) {
+	deck := mtgoDeck{
+		Xsd: "http://www.w3.org/2001/XMLSchema",
+		Xsi: "http://www.w3.org/2001/XMLSchema-instance",
+	}
+
+	byMtgoCard := func(card models.SetCard) string {
+		return fmt.Sprintf("%d|%s", card.MtgoID, card.Name)
+	}
+	for _, line := range groupDeckLines(main, byMtgoCard) {
+		deck.Cards = append(deck.Cards, mtgoDeckCard{CatID: line.card.MtgoID, Quantity: line.quantity, Name: line.card.Name})
+	}
+	for _, line := range groupDeckLines(sideboard, byMtgoCard) {
+		deck.Cards = append(deck.Cards, mtgoDeckCard{CatID: line.card.MtgoID, Quantity: line.quantity, Sideboard: true, Name: line.card.Name})
+	}
+
+	contents, err := xml.MarshalIndent(deck, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), contents...), nil
+}
+
+func exportArena(main, sideboard []models.SetCard) []byte {
+	var out bytes.Buffer
+	byPrinting := func(card models.SetCard) string {
+		return fmt.Sprintf("%s|%s|%s", card.Name, getCardSetCode(card), card.Number)
+	}
+	writeLines := func(cards []models.SetCard) {
+		for _, line := range groupDeckLines(cards, byPrinting) {
+			setCode := strings.ToUpper(getCardSetCode(line.card))
+			if arenaCode, ok := arenaSetCodes[setCode]; ok {
+				setCode = arenaCode
+			}
+
+			if setCode != "" && line.card.Number != "" {
+				out.WriteString(fmt.Sprintf("%d %s (%s) %s\n", line.quantity, line.card.Name, setCode, line.card.Number))
+			} else {
+				out.WriteString(fmt.Sprintf("%d %s\n", line.quantity, line.card.Name))
+			}
+		}
+	}
+
+	out.WriteString("Deck\n")
+	writeLines(main)
+	if len(sideboard) > 0 {
+		out.WriteString("\nSideboard\n")
+		writeLines(sideboard)
+	}
+	return out.Bytes()
+}
+
+func exportText(main, sideboard []models.SetCard) []byte {
+	var out bytes.Buffer
+	byName := func(card models.SetCard) string {
+		return card.Name
+	}
+	for _, line := range groupDeckLines(main, byName) {
+		out.WriteString(fmt.Sprintf("%d %s\n", line.quantity, line.card.Name))
+	}
+	if len(sideboard) > 0 {
+		out.WriteString("\n")
+		for _, line := range groupDeckLines(sideboard, byName) {
+			out.WriteString(fmt.Sprintf("%d %s\n", line.quantity, line.card.Name))
+		}
+	}
+	return out.Bytes()
+}
+
+// ExportDeck writes a deck in one of the MTGO .dek, Arena or plain text formats
+func ExportDeck(main, sideboard []models.SetCard, format string) ([]byte, error) {
+	switch format {
+	case MtgoExportFormat:
+		return exportMtgo(main, sideboard)
+	case ArenaExportFormat:
+		return exportArena(main, sideboard), nil
+	case TextExportFormat, "":
+		return exportText(main, sideboard), nil
+	default:
+		return nil, errors.New(fmt.Sprintf("unknown export format: %s", format))
+	}
+}
+
+// exportPool serves the requesting player's pool as a deck download, the
+// player is identified by their draft cookie. The pool is read on the Listen
+// loop.
+func (director *GameDirector) exportPool(w http.ResponseWriter, r *http.Request) {
+	_, seatToken := utils.HasDraftClientIDCookie(r, models.DraftCookieName)
+	var finished bool
+	var client *Client
+	var main, sideboard []models.SetCard
+	if !director.runOnLoop(func() {
+		finished = director.hasDraftFinished()
+		if client = director.Clients[director.seatTokens[seatToken]]; client != nil && finished {
+			main, sideboard = client.getDeckList()
+		}
+	}) {
+		http.Error(w, "the game is over", http.StatusGone)
+		return
+	}
+	if !finished {
+		http.Error(w, "pools can be exported once the draft is over", http.StatusConflict)
+		return
+	}
+	if seatToken == "" || client == nil {
+		http.Error(w, "not a player in this game", http.StatusForbidden)
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	contents, err := ExportDeck(main, sideboard, format)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	extension := "txt"
+	contentType := "text/plain; charset=utf-8"
+	if format == MtgoExportFormat {
+		extension = "dek"
+		contentType = "application/xml; charset=utf-8"
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", fmt.Sprintf("%s.%s", director.GameId, extension)))
+	_, _ = w.Write(contents)
+}
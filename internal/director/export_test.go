@@ -0,0 +1,285 @@
+package director
+
+import (
+	"encoding/json"
+	"github.com/gorilla/websocket"
+	"github.com/malexanderboyd/pwr9-godr4ft/internal/director/models"
+	"github.com/malexanderboyd/pwr9-godr4ft/internal/game"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// exposes unexported director internals to the director_test package
+
+// SixCardCube opens two three card packs, enough for one pack of a two seat draft
+const SixCardCube = "Black Lotus\nMox Pearl\nMox Sapphire\nMox Jet\nMox Ruby\nMox Emerald\n"
+
+// NewSeatedCubeDraft is a two seat, one pack draft of SixCardCube with
+// players seated first and bots in the rest, drafting with no pack dealt
+// yet. options can set anything but the players, type, mode and cube.
+func NewSeatedCubeDraft(t *testing.T, gameId string, options game.GeneralOptions, players int) (*GameDirector, []*Client) {
+	options.TotalPlayers, options.Type, options.Mode = 2, game.DRAFT, game.CUBE
+	options.GameOptions.Draft.Cube = game.DraftCubeOptions{CardsPerPack: 3, TotalPacks: 1, CubeList: SixCardCube}
+	director := NewGameDirector(options, 9000, gameId)
+	if err := director.getGameResources(); err != nil {
+		t.Fatal(err)
+	}
+	var clients []*Client
+	for i := 0; i < players; i++ {
+		client, _ := NewClient(director)
+		director.Clients[client.Id] = client
+		director.addToSeatOrder(client.Id)
+		clients = append(clients, client)
+	}
+	director.seatClients(options.TotalPlayers)
+	director.seatBots(options.TotalPlayers)
+	director.phase = models.PhaseDrafting
+	director.gameStarted = true
+	return director, clients
+}
+
+// NextMessageOfType drains what the director wrote to c, without a websocket,
+// up to the first msgType message. Without a wait it returns nil if there is
+// none, with one it keeps checking that long and fails the test if none comes.
+func NextMessageOfType(t *testing.T, c *Client, msgType models.GameMessageType, wait time.Duration) *models.Message {
+	deadline := time.Now().Add(wait)
+	for {
+		for msg := c.NextMessage(); msg != nil; msg = c.NextMessage() {
+			if msg.Type == msgType {
+				return msg
+			}
+		}
+		if wait == 0 {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expected a %s message", msgType)
+			return nil
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+// ReadUntil is NextMessageOfType for a client on the other end of ws
+func ReadUntil(t *testing.T, ws *websocket.Conn, msgType models.GameMessageType) models.Message {
+	_ = ws.SetReadDeadline(time.Now().Add(3 * time.Second))
+	var msg models.Message
+	for msg.Type != msgType {
+		if err := ws.ReadJSON(&msg); err != nil {
+			t.Fatalf("expected a %s message, got %v", msgType, err)
+		}
+	}
+	return msg
+}
+
+func ReadError(t *testing.T, ws *websocket.Conn) models.ErrorCode {
+	var errMsg models.ErrorJson
+	if err := json.Unmarshal(ReadUntil(t, ws, models.Error).Data, &errMsg); err != nil {
+		t.Fatal(err)
+	}
+	return errMsg.Code
+}
+
+func (director *GameDirector) GetGameResources() error {
+	return director.getGameResources()
+}
+
+func (director *GameDirector) RoundPacks() map[int]models.DraftRound {
+	return director.roundPacks
+}
+
+func (director *GameDirector) SealedPools() map[int][]models.SetCard {
+	return director.sealedPools
+}
+
+func (director *GameDirector) SeatBots(totalSeats int) {
+	director.seatBots(totalSeats)
+}
+
+func (director *GameDirector) SaveSnapshot(path string) {
+	director.snapshotPath = path
+	director.saveSnapshot()
+}
+
+// SetSnapshotPath saves the game to path from then on, picks are snapshotted
+// after delay instead of PickSnapshotDelay
+func (director *GameDirector) SetSnapshotPath(path string, delay time.Duration) {
+	director.snapshotPath = path
+	director.pickSnapshotDelay = delay
+}
+
+var GetSnapshotPath = getSnapshotPath
+
+// PlayerPicks, ForcedPicks and PickLatencyCount read the pick metrics, they
+// are shared by every game in the process so tests compare before and after
+func PlayerPicks() float64 {
+	return testutil.ToFloat64(picksTotal.WithLabelValues("player"))
+}
+
+func ForcedPicks() float64 {
+	return testutil.ToFloat64(forcedPicksTotal)
+}
+
+func PickLatencyCount(seat int) uint64 {
+	var metric dto.Metric
+	_ = pickLatency.WithLabelValues(strconv.Itoa(seat)).(prometheus.Metric).Write(&metric)
+	return metric.GetHistogram().GetSampleCount()
+}
+
+func (director *GameDirector) AdmitClient(claims *JoinClaims) (*Client, error) {
+	return director.admitClient("", claims)
+}
+
+func (server *GameServer) StartLoadingGames() {
+	server.startLoadingGames()
+}
+
+func (server *GameServer) ResumeCorrespondenceGames() {
+	server.resumeCorrespondenceGames()
+}
+
+func (director *GameDirector) ChooseCard(clientID string, index int, forced bool) error {
+	return director.handleClientChooseCard(clientID, director.getPick(clientID, index), forced)
+}
+
+func (director *GameDirector) SetPhase(phase models.GamePhase) {
+	director.phase = phase
+}
+
+func (director *GameDirector) ServeReplay(w http.ResponseWriter, r *http.Request) {
+	director.serveReplay(w, r)
+}
+
+func (c *Client) StartDeck() {
+	c.startDeck()
+}
+
+func (c *Client) MoveCard(uuid string, toSideboard bool) error {
+	return c.moveCard(uuid, toSideboard)
+}
+
+func (c *Client) SetBasicLands(lands map[string]int) error {
+	return c.setBasicLands(lands)
+}
+
+func (c *Client) DeckList() ([]models.SetCard, []models.SetCard) {
+	return c.getDeckList()
+}
+
+// RunOnLoop runs fn on a listening director's loop, for poking at its state
+// without racing it
+func (director *GameDirector) RunOnLoop(fn func()) {
+	director.runOnLoop(fn)
+}
+
+func (director *GameDirector) CheckDecks() bool {
+	return director.checkDecks()
+}
+
+func (director *GameDirector) StartTournament() {
+	director.startTournament()
+}
+
+func (director *GameDirector) Finish() {
+	director.shutdown()
+}
+
+func (director *GameDirector) SetCardSource(source CardSource) {
+	director.cardSource = source
+}
+
+func (director *GameDirector) SpectatorCount() int {
+	director.spectatorMu.Lock()
+	defer director.spectatorMu.Unlock()
+	return len(director.Spectators)
+}
+
+func (director *GameDirector) Host() string {
+	return director.host
+}
+
+func (director *GameDirector) SetHost(clientID string) {
+	director.host = clientID
+}
+
+func (director *GameDirector) SeatClients(totalSeats int) {
+	director.seatClients(totalSeats)
+}
+
+func (director *GameDirector) KickPlayer(clientID string) {
+	director.kickPlayer(clientID)
+}
+
+func (director *GameDirector) TickPickTimers() []int {
+	return director.tickPickTimers()
+}
+
+func (director *GameDirector) GetPickTimeRemaining(seat int) time.Duration {
+	director.pickMu.Lock()
+	defer director.pickMu.Unlock()
+	return director.getPickTimeRemaining(seat)
+}
+
+func (director *GameDirector) DealPack() {
+	director.dealPack()
+}
+
+func (director *GameDirector) SetGameStarted(started bool) {
+	director.gameStarted = started
+}
+
+// NextMessage is the next message written to the client, nil if there is none
+func (c *Client) NextMessage() *models.Message {
+	select {
+	case msg := <-c.ch:
+		return msg
+	default:
+		return nil
+	}
+}
+
+func (director *GameDirector) PickLog() []models.PickEvent {
+	return director.getPickLog()
+}
+
+func (director *GameDirector) SetNotifier(notifier Notifier) {
+	director.notifier = notifier
+}
+
+// AgePickTimer moves the seat's pick timer back as if its pack arrived earlier
+func (director *GameDirector) AgePickTimer(seat int, by time.Duration) {
+	director.pickMu.Lock()
+	defer director.pickMu.Unlock()
+	director.getPickTimer(seat).shownAt = director.getPickTimer(seat).shownAt.Add(-by)
+}
+
+func (c *Client) SetConnected(connected bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.connected = connected
+}
+
+// ExpireDeckbuildingTimer runs the host's deckbuilding timer out
+func (director *GameDirector) ExpireDeckbuildingTimer() {
+	director.deckbuildingTimer = time.Second
+	director.deckbuildingTimerStartedAt = time.Now().Add(-time.Second)
+}
+
+func (director *GameDirector) ShowSpectatorView(seq int, msg *models.Message) {
+	director.showSpectatorView(seq, msg)
+}
+
+func (director *GameDirector) SpectatorView() *models.Message {
+	director.spectatorMu.Lock()
+	defer director.spectatorMu.Unlock()
+	return director.spectatorView
+}
+
+func (director *GameDirector) SetChatLimitsByAddress(byAddress bool) {
+	director.chatLimitsByAddress = byAddress
+}
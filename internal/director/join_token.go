@@ -0,0 +1,138 @@
+package director
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// JoinClaims is what a join token vouches for, whoever holds the server's
+// join secret invited PlayerId to GameId under Name until ExpiresAt. PlayerId
+// is the player's identity in the game, it takes the same seat every time.
+type JoinClaims struct {
+	GameId   string `json:"gameId"`
+	PlayerId string `json:"playerId"`
+	Name     string `json:"name"`
+	// unix seconds
+	ExpiresAt int64 `json:"exp"`
+}
+
+// TokenClientIdPrefix starts the client id of a player who joined with a
+// token, so a token's PlayerId can never name a generated client or bot id
+const TokenClientIdPrefix = "t:"
+
+func (claims *JoinClaims) getClientId() string {
+	return TokenClientIdPrefix + claims.PlayerId
+}
+
+var joinTokenEncoding = base64.RawURLEncoding
+
+// NewJoinToken signs claims with secret, the token is the base64 claims JSON
+// and its HMAC-SHA256 joined by a dot
+func NewJoinToken(secret []byte, claims *JoinClaims) (string, error) {
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+	encoded := joinTokenEncoding.EncodeToString(payload)
+	return encoded + "." + joinTokenEncoding.EncodeToString(signJoinToken(secret, encoded)), nil
+}
+
+func signJoinToken(secret []byte, encodedClaims string) []byte {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(encodedClaims))
+	return mac.Sum(nil)
+}
+
+// ParseJoinToken checks the token's signature and expiry and returns its claims
+func ParseJoinToken(secret []byte, token string) (*JoinClaims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 2 {
+		return nil, errors.New("malformed join token")
+	}
+	signature, err := joinTokenEncoding.DecodeString(parts[1])
+	if err != nil || !hmac.Equal(signature, signJoinToken(secret, parts[0])) {
+		return nil, errors.New("join token signature does not match")
+	}
+	payload, err := joinTokenEncoding.DecodeString(parts[0])
+	if err != nil {
+		return nil, errors.New("malformed join token")
+	}
+
+	var claims JoinClaims
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return nil, errors.New("malformed join token")
+	}
+	if claims.PlayerId == "" {
+		return nil, errors.New("join token has no player")
+	}
+	if time.Now().Unix() >= claims.ExpiresAt {
+		return nil, errors.New("join token has expired")
+	}
+	return &claims, nil
+}
+
+// checkJoinToken reads the token query parameter, private games turn away
+// anyone without a valid token for this game. A public game takes a token
+// too, it is how a player keeps their seat across devices. There are no
+// claims and no error for a public game joined without one.
+func (director *GameDirector) checkJoinToken(r *http.Request) (*JoinClaims, error) {
+	token := r.URL.Query().Get("token")
+	if token == "" {
+		if director.options.PrivateGame {
+			return nil, errors.New("this game is private, join with the link you were invited with")
+		}
+		return nil, nil
+	}
+	if len(director.joinSecret) == 0 {
+		return nil, errors.New("the server cannot check join tokens")
+	}
+
+	claims, err := ParseJoinToken(director.joinSecret, token)
+	if err != nil {
+		return nil, err
+	}
+	if claims.GameId != director.GameId {
+		return nil, errors.New(fmt.Sprintf("join token is for game %s", claims.GameId))
+	}
+	return claims, nil
+}
+
+// checkOrigin lets browsers on the allowed origins open a websocket, every
+// origin is allowed when none are configured. Requests without an Origin
+// don't come from a browser page and are let through.
+func (director *GameDirector) checkOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" || len(director.allowedOrigins) == 0 {
+		return true
+	}
+	u, err := url.Parse(origin)
+	if err != nil {
+		return false
+	}
+	for _, allowed := range director.allowedOrigins {
+		if strings.EqualFold(strings.TrimSpace(allowed), u.Scheme+"://"+u.Host) {
+			return true
+		}
+	}
+	return false
+}
+
+// requireJoinToken turns away requests without a valid token for this game
+// when it is private, the same as joining its websocket
+func (director *GameDirector) requireJoinToken(handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if _, err := director.checkJoinToken(r); err != nil {
+			http.Error(w, err.Error(), http.StatusForbidden)
+			return
+		}
+		handler(w, r)
+	}
+}
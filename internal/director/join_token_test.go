@@ -0,0 +1,103 @@
+package director_test
+
+import (
+	"github.com/gorilla/websocket"
+	"github.com/malexanderboyd/pwr9-godr4ft/internal/director"
+	"github.com/malexanderboyd/pwr9-godr4ft/internal/director/models"
+	"github.com/malexanderboyd/pwr9-godr4ft/internal/game"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+var joinSecret = []byte("league secret")
+
+func TestJoinTokens(t *testing.T) {
+	claims := &director.JoinClaims{GameId: "g", PlayerId: "alice", Name: "Alice", ExpiresAt: time.Now().Add(time.Hour).Unix()}
+	token, err := director.NewJoinToken(joinSecret, claims)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, err := director.ParseJoinToken(joinSecret, token)
+	if err != nil || *parsed != *claims {
+		t.Errorf("expected the claims back, got %+v %v", parsed, err)
+	}
+
+	if _, err := director.ParseJoinToken([]byte("another secret"), token); err == nil {
+		t.Errorf("expected a token signed with another secret to be rejected")
+	}
+	forged, _ := director.NewJoinToken([]byte("another secret"), &director.JoinClaims{GameId: "g", PlayerId: "mallory", ExpiresAt: claims.ExpiresAt})
+	if _, err := director.ParseJoinToken(joinSecret, strings.Split(forged, ".")[0]+"."+strings.Split(token, ".")[1]); err == nil {
+		t.Errorf("expected claims swapped under another signature to be rejected")
+	}
+	expired, _ := director.NewJoinToken(joinSecret, &director.JoinClaims{GameId: "g", PlayerId: "alice", ExpiresAt: time.Now().Add(-time.Minute).Unix()})
+	if _, err := director.ParseJoinToken(joinSecret, expired); err == nil {
+		t.Errorf("expected an expired token to be rejected")
+	}
+}
+
+func TestPrivateGameNeedsAJoinToken(t *testing.T) {
+	server := director.NewGameServer(director.ServerConfig{
+		JoinSecret:     joinSecret,
+		AllowedOrigins: []string{"https://draft.example.com"},
+	})
+	d := director.NewGameDirector(game.GeneralOptions{PrivateGame: true}, 9000, "private_game")
+	server.AddGame(d)
+	ts := httptest.NewServer(server.Handler())
+	defer ts.Close()
+	wsUrl := "ws" + strings.TrimPrefix(ts.URL, "http") + "/game/private_game/ws"
+
+	if _, res, err := websocket.DefaultDialer.Dial(wsUrl, nil); err == nil || res.StatusCode != http.StatusForbidden {
+		t.Fatalf("expected a player without a token to be turned away")
+	}
+	otherGame, _ := director.NewJoinToken(joinSecret, &director.JoinClaims{GameId: "other_game", PlayerId: "alice", ExpiresAt: time.Now().Add(time.Hour).Unix()})
+	if _, res, err := websocket.DefaultDialer.Dial(wsUrl+"?token="+otherGame, nil); err == nil || res.StatusCode != http.StatusForbidden {
+		t.Fatalf("expected a token for another game to be turned away")
+	}
+
+	token, _ := director.NewJoinToken(joinSecret, &director.JoinClaims{GameId: "private_game", PlayerId: "alice", Name: "Alice", ExpiresAt: time.Now().Add(time.Hour).Unix()})
+	header := http.Header{}
+	header.Set("Origin", "https://elsewhere.example.com")
+	if _, res, err := websocket.DefaultDialer.Dial(wsUrl+"?token="+token, header); err == nil || res.StatusCode != http.StatusForbidden {
+		t.Fatalf("expected a page on another origin to be turned away")
+	}
+
+	header.Set("Origin", "https://draft.example.com")
+	for i := 0; i < 2; i++ {
+		ws, res, err := websocket.DefaultDialer.Dial(wsUrl+"?token="+token, header)
+		if err != nil {
+			t.Fatalf("expected an invited player to join, got %v", err)
+		}
+		if cookie := res.Header.Get("Set-Cookie"); strings.Contains(cookie, "alice") {
+			t.Errorf("expected the cookie to hold a seat token rather than the player id, got %q", cookie)
+		}
+		var roster models.RosterJson
+		_ = director.ReadUntil(t, ws, models.Roster).Decode(&roster)
+		if len(roster.Players) != 1 || roster.Players[0].Id != director.TokenClientIdPrefix+"alice" || roster.Players[0].Name != "Alice" {
+			t.Errorf("expected alice to be the only player, got %+v", roster.Players)
+		}
+		ws.Close()
+	}
+
+	for _, path := range []string{"/status", "/replay", "/export"} {
+		url := ts.URL + "/game/private_game" + path
+		if res, err := http.Get(url); err != nil || res.StatusCode != http.StatusForbidden {
+			t.Errorf("expected %s of a private game to need a token, got %v %v", path, res, err)
+		}
+		if res, err := http.Get(url + "?token=" + token); err != nil || res.StatusCode == http.StatusForbidden {
+			t.Errorf("expected %s to answer an invited player, got %v %v", path, res, err)
+		}
+	}
+
+	bob := &director.JoinClaims{GameId: "private_game", PlayerId: "bob"}
+	var first, second error
+	d.RunOnLoop(func() {
+		_, first = d.AdmitClient(bob)
+		_, second = d.AdmitClient(bob)
+	})
+	if first != nil || second == nil {
+		t.Errorf("expected a second connection with the same token to wait for the first, got %v %v", first, second)
+	}
+}
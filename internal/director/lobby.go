@@ -0,0 +1,213 @@
+package director
+
+import (
+	"errors"
+	"fmt"
+	"github.com/malexanderboyd/pwr9-godr4ft/internal"
+	"github.com/malexanderboyd/pwr9-godr4ft/internal/director/models"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+const MaxDisplayNameLength = 24
+
+// addToSeatOrder puts a player joining the lobby in the next open seat
+func (director *GameDirector) addToSeatOrder(clientID string) {
+	director.lobbyMu.Lock()
+	defer director.lobbyMu.Unlock()
+	for _, id := range director.seatOrder {
+		if id == clientID {
+			return
+		}
+	}
+	director.seatOrder = append(director.seatOrder, clientID)
+}
+
+func (director *GameDirector) removeFromSeatOrder(clientID string) {
+	director.lobbyMu.Lock()
+	defer director.lobbyMu.Unlock()
+	director.seatOrder = remove(director.seatOrder, clientID)
+}
+
+func remove(ids []string, id string) []string {
+	var rest []string
+	for _, other := range ids {
+		if other != id {
+			rest = append(rest, other)
+		}
+	}
+	return rest
+}
+
+func (director *GameDirector) getSeatOrder() []string {
+	director.lobbyMu.Lock()
+	defer director.lobbyMu.Unlock()
+	return append([]string{}, director.seatOrder...)
+}
+
+// getLobbySeat is the seat a lobby player will take when the game starts
+func (director *GameDirector) getLobbySeat(clientID string) int {
+	for i, id := range director.getSeatOrder() {
+		if id == clientID {
+			if director.options.TotalPlayers > 0 && i >= director.options.TotalPlayers {
+				return -1
+			}
+			return i
+		}
+	}
+	return -1
+}
+
+func (director *GameDirector) getRosterMessage() *models.Message {
+	return models.NewMessage(models.Roster, director.getRoster())
+}
+
+// getRoster lists players and bots in seat order, players who never set a
+// name are named by where they sit
+func (director *GameDirector) getRoster() *models.RosterJson {
+	var roster models.RosterJson
+	for id, c := range director.Clients {
+		seat := -1
+		if director.gameStarted {
+			if s, seated := director.Seats[id]; seated {
+				seat = s
+			}
+		} else {
+			seat = director.getLobbySeat(id)
+		}
+		roster.Players = append(roster.Players, models.RosterPlayerJson{
+			Id:        id,
+			Name:      c.getName(),
+			Seat:      seat,
+			Ready:     c.isReady(),
+			Host:      id == director.host,
+			Connected: c.IsConnected(),
+			Muted:     director.isMuted(id),
+		})
+	}
+	for id := range director.Bots {
+		roster.Players = append(roster.Players, models.RosterPlayerJson{
+			Id:        id,
+			Name:      fmt.Sprintf("Bot %d", director.Seats[id]+1),
+			Seat:      director.Seats[id],
+			Ready:     true,
+			Bot:       true,
+			Connected: true,
+		})
+	}
+	sort.Slice(roster.Players, func(i, j int) bool {
+		a, b := roster.Players[i], roster.Players[j]
+		if a.Seat != b.Seat {
+			// unseated players go last
+			return uint(a.Seat) < uint(b.Seat)
+		}
+		return a.Id < b.Id
+	})
+	for i := range roster.Players {
+		if roster.Players[i].Name == "" {
+			roster.Players[i].Name = fmt.Sprintf("Player %d", i+1)
+		}
+	}
+
+	return &roster
+}
+
+func (director *GameDirector) sendRoster() {
+	director.sendAll(director.getRosterMessage())
+}
+
+func getDisplayName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || utf8.RuneCountInString(name) > MaxDisplayNameLength {
+		return "", errors.New(fmt.Sprintf("display names must be 1 to %d characters", MaxDisplayNameLength))
+	}
+	return name, nil
+}
+
+// setNameFromClaims names a player after their join token, a name the lobby
+// wouldn't take is left for the player to set
+func setNameFromClaims(client *Client, claims *JoinClaims) {
+	if name, err := getDisplayName(claims.Name); err == nil {
+		client.setName(name)
+	}
+}
+
+func (director *GameDirector) handleClientSetName(clientID string, nameMsg *models.SetNameJson) error {
+	client, ok := director.Clients[clientID]
+	if !ok {
+		return errors.New(fmt.Sprintf("No client with id: %s. Must provide valid client ID", clientID))
+	}
+
+	name, err := getDisplayName(nameMsg.Name)
+	if err != nil {
+		return err
+	}
+
+	client.setName(name)
+	director.sendRoster()
+	return nil
+}
+
+func (director *GameDirector) handleClientSetReady(clientID string, readyMsg *models.SetReadyJson) error {
+	client, ok := director.Clients[clientID]
+	if !ok {
+		return errors.New(fmt.Sprintf("No client with id: %s. Must provide valid client ID", clientID))
+	}
+
+	client.setReady(readyMsg.Ready)
+	director.sendRoster()
+	if director.areEnoughPlayersReady() {
+		internal.GetLogger().Infow("All players are ready, starting game", "game", director.GameId)
+		director.beginGame(&models.TimerSettings{Type: director.roundTimerType})
+	}
+	return nil
+}
+
+// areEnoughPlayersReady is true once a full table of connected players is ready
+func (director *GameDirector) areEnoughPlayersReady() bool {
+	if director.options.TotalPlayers < 1 {
+		return false
+	}
+	ready := 0
+	for _, id := range director.getSeatOrder() {
+		if c, ok := director.Clients[id]; ok && c.IsConnected() && c.isReady() {
+			ready++
+		}
+	}
+	return ready >= director.options.TotalPlayers
+}
+
+func (director *GameDirector) handleHostRandomizeSeats() {
+	director.lobbyMu.Lock()
+	director.rng.Shuffle(len(director.seatOrder), func(i, j int) {
+		director.seatOrder[i], director.seatOrder[j] = director.seatOrder[j], director.seatOrder[i]
+	})
+	director.lobbyMu.Unlock()
+	director.sendRoster()
+}
+
+func (director *GameDirector) handleHostArrangeSeats(arrangeMsg *models.ArrangeSeatsJson) error {
+	director.lobbyMu.Lock()
+	current := make(map[string]bool)
+	for _, id := range director.seatOrder {
+		current[id] = true
+	}
+	arranged := make(map[string]bool)
+	for _, id := range arrangeMsg.PlayerIds {
+		if !current[id] || arranged[id] {
+			director.lobbyMu.Unlock()
+			return errors.New(fmt.Sprintf("cannot seat %s, seating must list every lobby player once", id))
+		}
+		arranged[id] = true
+	}
+	if len(arranged) != len(current) {
+		director.lobbyMu.Unlock()
+		return errors.New("seating must list every lobby player once")
+	}
+	director.seatOrder = append([]string{}, arrangeMsg.PlayerIds...)
+	director.lobbyMu.Unlock()
+
+	director.sendRoster()
+	return nil
+}
@@ -0,0 +1,138 @@
+package director_test
+
+import (
+	"github.com/gorilla/websocket"
+	"github.com/malexanderboyd/pwr9-godr4ft/internal/director"
+	"github.com/malexanderboyd/pwr9-godr4ft/internal/director/models"
+	"github.com/malexanderboyd/pwr9-godr4ft/internal/game"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func readRoster(t *testing.T, ws *websocket.Conn) models.RosterJson {
+	var roster models.RosterJson
+	if err := director.ReadUntil(t, ws, models.Roster).Decode(&roster); err != nil {
+		t.Fatal(err)
+	}
+	return roster
+}
+
+func TestLobbyNamesAndSeating(t *testing.T) {
+	var options game.GeneralOptions
+	d := director.NewGameDirector(options, 9000, "lobby_game")
+	go d.Listen()
+	defer d.Finish()
+
+	ts := httptest.NewServer(d.Handler())
+	defer ts.Close()
+	wsUrl := "ws" + strings.TrimPrefix(ts.URL, "http") + "/ws"
+
+	host, _, err := websocket.DefaultDialer.Dial(wsUrl, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer host.Close()
+	readRoster(t, host)
+
+	player, _, err := websocket.DefaultDialer.Dial(wsUrl, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer player.Close()
+	roster := readRoster(t, player)
+	if len(roster.Players) != 2 || !roster.Players[0].Host || roster.Players[0].Seat != 0 || roster.Players[1].Seat != 1 {
+		t.Fatalf("expected the host in seat 0 and the player in seat 1, got %+v", roster.Players)
+	}
+	hostID, playerID := roster.Players[0].Id, roster.Players[1].Id
+
+	_ = player.WriteJSON(models.NewMessage(models.SetName, &models.SetNameJson{Name: "  Garfield  "}))
+	roster = readRoster(t, player)
+	if roster.Players[1].Name != "Garfield" {
+		t.Errorf("expected a trimmed display name, got %q", roster.Players[1].Name)
+	}
+
+	_ = player.WriteJSON(models.NewMessage(models.SetName, &models.SetNameJson{Name: strings.Repeat("x", director.MaxDisplayNameLength+1)}))
+	if code := director.ReadError(t, player); code != models.ErrRejected {
+		t.Errorf("expected a long name to be rejected, got %q", code)
+	}
+
+	_ = player.WriteJSON(&models.Message{Type: models.RandomizeSeats})
+	if code := director.ReadError(t, player); code != models.ErrUnauthorized {
+		t.Errorf("expected a not the host error, got %q", code)
+	}
+	_ = player.WriteJSON(&models.Message{Type: models.GameStart})
+	if code := director.ReadError(t, player); code != models.ErrUnauthorized {
+		t.Errorf("expected a not the host error, got %q", code)
+	}
+
+	_ = host.WriteJSON(models.NewMessage(models.ArrangeSeats, &models.ArrangeSeatsJson{PlayerIds: []string{hostID}}))
+	director.ReadUntil(t, host, models.Error)
+
+	_ = host.WriteJSON(models.NewMessage(models.ArrangeSeats, &models.ArrangeSeatsJson{PlayerIds: []string{playerID, hostID}}))
+	roster = readRoster(t, host)
+	if roster.Players[0].Id != playerID || roster.Players[0].Seat != 0 || roster.Players[1].Id != hostID {
+		t.Errorf("expected the player to be moved to seat 0, got %+v", roster.Players)
+	}
+
+	readRoster(t, player)
+	_ = player.WriteJSON(models.NewMessage(models.SetReady, &models.SetReadyJson{Ready: true}))
+	roster = readRoster(t, player)
+	if !roster.Players[0].Ready || roster.Players[1].Ready {
+		t.Errorf("expected only the player to be ready, got %+v", roster.Players)
+	}
+}
+
+func TestReadyTableStartsTheGameOnce(t *testing.T) {
+	options := game.GeneralOptions{TotalPlayers: 3, Type: game.DRAFT, Mode: game.CUBE}
+	options.GameOptions.Draft.Cube = game.DraftCubeOptions{
+		CardsPerPack: 2,
+		TotalPacks:   1,
+		CubeList:     director.SixCardCube,
+	}
+	d := director.NewGameDirector(options, 9000, "ready_once_game")
+	if err := d.GetGameResources(); err != nil {
+		t.Fatal(err)
+	}
+	go d.Listen()
+	defer d.Finish()
+
+	ts := httptest.NewServer(d.Handler())
+	defer ts.Close()
+	wsUrl := "ws" + strings.TrimPrefix(ts.URL, "http") + "/ws"
+
+	var players []*websocket.Conn
+	for i := 0; i < options.TotalPlayers; i++ {
+		player, _, err := websocket.DefaultDialer.Dial(wsUrl, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer player.Close()
+		readRoster(t, player)
+		players = append(players, player)
+	}
+	// everyone readies at once, only one of them can be the last
+	for _, player := range players {
+		go func(player *websocket.Conn) {
+			_ = player.WriteJSON(models.NewMessage(models.SetReady, &models.SetReadyJson{Ready: true}))
+		}(player)
+	}
+
+	for _, player := range players {
+		starts := 0
+		_ = player.SetReadDeadline(time.Now().Add(time.Second))
+		for {
+			var msg models.Message
+			if err := player.ReadJSON(&msg); err != nil {
+				break
+			}
+			if msg.Type == models.GameStart {
+				starts++
+			}
+		}
+		if starts != 1 {
+			t.Errorf("expected the game to start once, got %d start_game messages", starts)
+		}
+	}
+}
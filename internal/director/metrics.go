@@ -0,0 +1,62 @@
+package director
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"strconv"
+	"time"
+)
+
+// Prometheus metrics across every game the server hosts, served on /metrics
+var (
+	connectedClients = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "godr4ft_connected_clients",
+		Help: "Open websocket connections of players and spectators.",
+	})
+	activeGames = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "godr4ft_active_games",
+		Help: "Games the server is hosting.",
+	})
+	picksTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "godr4ft_picks_total",
+		Help: "Picks made, by whether a player or a bot made them.",
+	}, []string{"drafter"})
+	forcedPicksTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "godr4ft_forced_picks_total",
+		Help: "Picks the server made for players who ran out of time or were forced on by the host.",
+	})
+	pickLatency = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name: "godr4ft_pick_latency_seconds",
+		Help: "Time from a pack reaching a player to their pick, by seat.",
+		// a live pick takes seconds, a correspondence pick can take a day
+		Buckets: prometheus.ExponentialBuckets(1, 2, 18),
+	}, []string{"seat"})
+	wsWriteFailures = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "godr4ft_ws_write_failures_total",
+		Help: "Messages that could not be written to a websocket, the connection is closed after each.",
+	})
+	bufferFullDisconnects = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "godr4ft_buffer_full_disconnects_total",
+		Help: "Clients disconnected because they fell too far behind to buffer their messages.",
+	})
+	upstreamFetchLatency = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name: "godr4ft_upstream_fetch_seconds",
+		Help: "Time taken by requests to the API for game options, sets and boosters.",
+	}, []string{"call"})
+)
+
+func observePick(seat int, isBot bool, forced bool, shownAt time.Time) {
+	if isBot {
+		picksTotal.WithLabelValues("bot").Inc()
+		return
+	}
+	picksTotal.WithLabelValues("player").Inc()
+	if forced {
+		forcedPicksTotal.Inc()
+	}
+	pickLatency.WithLabelValues(strconv.Itoa(seat)).Observe(time.Since(shownAt).Seconds())
+}
+
+func observeUpstreamFetch(call string, startedAt time.Time) {
+	upstreamFetchLatency.WithLabelValues(call).Observe(time.Since(startedAt).Seconds())
+}
@@ -0,0 +1,45 @@
+package director_test
+
+import (
+	"github.com/malexanderboyd/pwr9-godr4ft/internal/director"
+	"github.com/malexanderboyd/pwr9-godr4ft/internal/game"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"io/ioutil"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestPicksAreInMetrics(t *testing.T) {
+	d, clients := director.NewSeatedCubeDraft(t, "metrics_game", game.GeneralOptions{}, 1)
+	client := clients[0]
+	d.DealPack()
+	picks, forced, latencies := director.PlayerPicks(), director.ForcedPicks(), director.PickLatencyCount(0)
+	if err := d.ChooseCard(client.Id, 0, true); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if got := director.PlayerPicks() - picks; got != 1 {
+		t.Errorf("expected the player's pick to be counted once, got %v", got)
+	}
+	if got := director.ForcedPicks() - forced; got != 1 {
+		t.Errorf("expected the forced pick to be counted once, got %v", got)
+	}
+	if got := director.PickLatencyCount(0) - latencies; got != 1 {
+		t.Errorf("expected one pick latency for seat 0, got %d", got)
+	}
+
+	rec := httptest.NewRecorder()
+	promhttp.Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	body, _ := ioutil.ReadAll(rec.Body)
+	for _, metric := range []string{
+		`godr4ft_picks_total{drafter="player"}`,
+		"godr4ft_forced_picks_total",
+		`godr4ft_pick_latency_seconds_bucket{seat="0"`,
+		"godr4ft_connected_clients",
+		"godr4ft_active_games",
+	} {
+		if !strings.Contains(string(body), metric) {
+			t.Errorf("expected %s in the metrics", metric)
+		}
+	}
+}
@@ -0,0 +1,27 @@
+package models
+
+type KickPlayerJson struct {
+	PlayerId string `json:"playerId"`
+}
+
+type ExtendRoundJson struct {
+	Seconds int `json:"seconds"`
+}
+
+type MutePlayerJson struct {
+	PlayerId string `json:"playerId"`
+	Muted    bool   `json:"muted"`
+}
+
+// RetractChatJson names a chat message by its id, the host sends it to take
+// the message down and the server sends it on so clients drop it too
+type RetractChatJson struct {
+	MessageId int `json:"messageId"`
+}
+
+// RoundTimerJson tells players the pick timer changed, Seconds is what is
+// left of the round
+type RoundTimerJson struct {
+	Seconds int  `json:"seconds"`
+	Paused  bool `json:"paused"`
+}
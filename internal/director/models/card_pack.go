@@ -1,9 +1,15 @@
 package models
 
+import "time"
+
 type CardPack struct {
-	SetName    string          `json:"setName"`
-	Round      int             `json:"round"`
-	PackNumber int             `json:"packNumber"`
+	SetName    string    `json:"setName"`
+	Round      int       `json:"round"`
+	PackNumber int       `json:"packNumber"`
 	Pack       []SetCard `json:"pack"`
-	Timer      int             `json:"timer"`
+	Timer      int       `json:"timer"`
+	// packs waiting behind this one
+	Queued int `json:"queued"`
+	// when the server picks for a correspondence drafter
+	Deadline *time.Time `json:"deadline,omitempty"`
 }
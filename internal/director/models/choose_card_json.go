@@ -1,5 +1,19 @@
 package models
 
+// ChooseCardJson names the picked card by UUID along with the pack number and
+// round of the round_content it came from, so a pick can never land on a pack
+// the player was not looking at
 type ChooseCardJson struct {
-	PickedCardIndex int `json:"pickedCardIndex"`
+	UUID       string `json:"uuid"`
+	PackNumber int    `json:"packNumber"`
+	Round      int    `json:"round"`
+}
+
+// PickConfirmedJson tells a player which card went into their pool, Forced
+// is set when the server picked for them
+type PickConfirmedJson struct {
+	Card       CardRef `json:"card"`
+	PackNumber int     `json:"packNumber"`
+	Round      int     `json:"round"`
+	Forced     bool    `json:"forced"`
 }
@@ -5,15 +5,54 @@ import "time"
 const DraftCookieName = "pwr9_draft"
 const NoHostSentinel = "-999"
 const (
-	NewPlayer    GameMessageType = "new_player"
-	ChatMessage  GameMessageType = "chat_message"
-	HostChange   GameMessageType = "host_change"
-	GameStart    GameMessageType = "start_game"
-	GameEnd      GameMessageType = "end_game"
-	RoundContent GameMessageType = "round_content"
-	PoolContent  GameMessageType = "pool_content"
-	ChooseCard   GameMessageType = "choose_card"
+	Hello         GameMessageType = "hello"
+	Welcome       GameMessageType = "welcome"
+	NewPlayer     GameMessageType = "new_player"
+	ChatMessage   GameMessageType = "chat_message"
+	HostChange    GameMessageType = "host_change"
+	GameStart     GameMessageType = "start_game"
+	GameEnd       GameMessageType = "end_game"
+	RoundContent  GameMessageType = "round_content"
+	PoolContent   GameMessageType = "pool_content"
+	ChooseCard    GameMessageType = "choose_card"
+	PickConfirmed GameMessageType = "pick_confirmed"
+	PhaseChange   GameMessageType = "phase_change"
+	Error         GameMessageType = "error"
+	SpectatorView GameMessageType = "spectator_view"
+	PickStatus    GameMessageType = "pick_status"
+	// deckbuilding
+	DeckContent       GameMessageType = "deck_content"
+	MoveCard          GameMessageType = "move_card"
+	SetBasicLands     GameMessageType = "set_basic_lands"
+	DeckReady         GameMessageType = "deck_ready"
+	DeckbuildingTimer GameMessageType = "deckbuilding_timer"
+	DecksLocked       GameMessageType = "decks_locked"
+	// lobby
+	Roster         GameMessageType = "roster"
+	SetName        GameMessageType = "set_name"
+	SetReady       GameMessageType = "set_ready"
+	RandomizeSeats GameMessageType = "randomize_seats"
+	ArrangeSeats   GameMessageType = "arrange_seats"
+	// host commands
+	KickPlayer   GameMessageType = "kick_player"
+	Kicked       GameMessageType = "kicked"
+	PauseTimer   GameMessageType = "pause_timer"
+	ResumeTimer  GameMessageType = "resume_timer"
+	ExtendRound  GameMessageType = "extend_round"
+	ForceAdvance GameMessageType = "force_advance"
+	EndGameEarly GameMessageType = "end_game_early"
+	RoundTimer   GameMessageType = "round_timer"
+	// chat moderation
+	MutePlayer    GameMessageType = "mute_player"
+	RetractChat   GameMessageType = "retract_chat"
+	ChatRetracted GameMessageType = "chat_retracted"
+	// swiss tournament
+	Pairings    GameMessageType = "pairings"
+	MatchResult GameMessageType = "match_result"
+	MatchUpdate GameMessageType = "match_update"
+	Standings   GameMessageType = "standings"
 )
+
 var (
 	Newline = []byte{'\n'}
 	Space   = []byte{' '}
@@ -21,6 +60,20 @@ var (
 
 const ChannelBufSize = 100
 
+var BasicLandNames = []string{"Plains", "Island", "Swamp", "Mountain", "Forest", "Wastes"}
+
+func IsBasicLandName(name string) bool {
+	for _, basic := range BasicLandNames {
+		if name == basic {
+			return true
+		}
+	}
+	return false
+}
+
+// most copies of one basic land a deck can ask for
+const MaxBasicLands = 60
+
 const (
 	// Time allowed to write a message to the peer
 	WriteWait = 10 * time.Second
@@ -29,6 +82,7 @@ const (
 	// Send pings to peer with this period. Must be less than pongWait
 	PingPeriod = (PongWait * 9) / 10
 
-	// Maximum message size allowed from peer
-	MaxMessageSize = 512
-)
\ No newline at end of file
+	// Maximum message size allowed from peer, room for the longest chat
+	// message in multibyte text
+	MaxMessageSize = 2048
+)
@@ -0,0 +1,24 @@
+package models
+
+// Deck is a player's deckbuilding state, cards move between Main and
+// Sideboard and basic lands are added by name on top of the pool.
+type Deck struct {
+	Main       []SetCard      `json:"main"`
+	Sideboard  []SetCard      `json:"sideboard"`
+	BasicLands map[string]int `json:"basicLands"`
+	Ready      bool           `json:"ready"`
+	Locked     bool           `json:"locked"`
+}
+
+type MoveCardJson struct {
+	UUID        string `json:"uuid"`
+	ToSideboard bool   `json:"toSideboard"`
+}
+
+type DeckReadyJson struct {
+	Ready bool `json:"ready"`
+}
+
+type DeckbuildingTimerJson struct {
+	Seconds int `json:"seconds"`
+}
@@ -3,8 +3,25 @@ package models
 type DraftRound struct {
 	SetAbbreviation string
 	PlayerPacks     map[int][]SetCard
+	// set of each seat's opened pack when packs in a round differ, ie: chaos drafts
+	PackSetNames map[int]string
 }
 
 func (dr *DraftRound) getPlayerPacksBySeat(playerSeatNumber int) []SetCard {
 	return dr.PlayerPacks[playerSeatNumber]
 }
+
+func (dr DraftRound) GetPackSetName(playerSeatNumber int) string {
+	if setName, ok := dr.PackSetNames[playerSeatNumber]; ok {
+		return setName
+	}
+	return dr.SetAbbreviation
+}
+
+// QueuedPack is a pack on its way around the table, Pick is the pick number
+// for whoever picks from it next
+type QueuedPack struct {
+	SetName string    `json:"setName"`
+	Cards   []SetCard `json:"cards"`
+	Pick    int       `json:"pick"`
+}
@@ -0,0 +1,11 @@
+package models
+
+type GamePhase string
+
+const (
+	PhaseLobby        GamePhase = "lobby"
+	PhaseDrafting     GamePhase = "drafting"
+	PhaseDeckbuilding GamePhase = "deckbuilding"
+	PhaseTournament   GamePhase = "tournament"
+	PhaseEnded        GamePhase = "ended"
+)
@@ -0,0 +1,31 @@
+package models
+
+// RosterPlayerJson is one player in the roster, Seat is -1 for anyone
+// without a seat
+type RosterPlayerJson struct {
+	Id        string `json:"id"`
+	Name      string `json:"name"`
+	Seat      int    `json:"seat"`
+	Ready     bool   `json:"ready"`
+	Host      bool   `json:"host"`
+	Bot       bool   `json:"bot"`
+	Connected bool   `json:"connected"`
+	Muted     bool   `json:"muted"`
+}
+
+type RosterJson struct {
+	Players []RosterPlayerJson `json:"players"`
+}
+
+type SetNameJson struct {
+	Name string `json:"name"`
+}
+
+type SetReadyJson struct {
+	Ready bool `json:"ready"`
+}
+
+// ArrangeSeatsJson lists every lobby player's id in seat order
+type ArrangeSeatsJson struct {
+	PlayerIds []string `json:"playerIds"`
+}
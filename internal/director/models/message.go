@@ -1,6 +1,41 @@
 package models
 
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// Message is the envelope for everything sent over the websocket, Data holds
+// the message type's payload as plain JSON, see GetMessageSpec for which
+// payload goes with which type.
 type Message struct {
-	Type GameMessageType `json:"type"`
-	Data string          `json:"data"`
+	Version int             `json:"v,omitempty"`
+	Type    GameMessageType `json:"type"`
+	Data    json.RawMessage `json:"data,omitempty"`
+}
+
+// NewMessage wraps payload in an envelope for the current protocol version.
+// Payloads are always our own structs, so one that cannot be marshalled is a bug.
+func NewMessage(msgType GameMessageType, payload interface{}) *Message {
+	msg := &Message{
+		Version: ProtocolVersion,
+		Type:    msgType,
+	}
+	if payload != nil {
+		data, err := json.Marshal(payload)
+		if err != nil {
+			panic(fmt.Sprintf("cannot marshal %s payload: %v", msgType, err))
+		}
+		msg.Data = data
+	}
+	return msg
+}
+
+// Decode unmarshals the message's payload into v
+func (msg Message) Decode(v interface{}) error {
+	if len(msg.Data) == 0 {
+		return errors.New(fmt.Sprintf("%s message has no data", msg.Type))
+	}
+	return json.Unmarshal(msg.Data, v)
 }
@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+// PackWaitingNotice tells a correspondence drafter a pack is waiting on them
+type PackWaitingNotice struct {
+	GameId     string `json:"gameId"`
+	PlayerId   string `json:"playerId"`
+	Name       string `json:"name"`
+	Seat       int    `json:"seat"`
+	PackNumber int    `json:"packNumber"`
+	Pick       int    `json:"pick"`
+	// the server picks for them after this
+	Deadline time.Time `json:"deadline"`
+}
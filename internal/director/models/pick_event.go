@@ -0,0 +1,41 @@
+package models
+
+import "time"
+
+// CardRef is the part of a SetCard worth keeping in a draft log
+type CardRef struct {
+	UUID    string `json:"uuid"`
+	Name    string `json:"name"`
+	SetCode string `json:"setCode"`
+	Number  string `json:"number"`
+	Rarity  string `json:"rarity"`
+}
+
+func NewCardRef(card SetCard) CardRef {
+	return CardRef{
+		UUID:    card.UUID,
+		Name:    card.Name,
+		SetCode: card.SetCode,
+		Number:  card.Number,
+		Rarity:  card.Rarity,
+	}
+}
+
+type PickEvent struct {
+	Seat       int       `json:"seat"`
+	PlayerId   string    `json:"playerId"`
+	PackNumber int       `json:"packNumber"`
+	PickNumber int       `json:"pickNumber"`
+	Pack       []CardRef `json:"pack"`
+	Picked     CardRef   `json:"picked"`
+	// the server picked for a player who ran out of time
+	Forced      bool      `json:"forced"`
+	TimeTakenMs int64     `json:"timeTakenMs"`
+	PickedAt    time.Time `json:"pickedAt"`
+}
+
+type DraftReplay struct {
+	GameId string         `json:"gameId"`
+	Seats  map[string]int `json:"seats"`
+	Picks  []PickEvent    `json:"picks"`
+}
@@ -0,0 +1,23 @@
+package models
+
+// PickStatusJson shows the table who the draft is waiting on, it goes out
+// after every pick
+type PickStatusJson struct {
+	PackNumber int                  `json:"packNumber"`
+	Seats      []PickStatusSeatJson `json:"seats"`
+}
+
+type PickStatusSeatJson struct {
+	Seat     int    `json:"seat"`
+	PlayerId string `json:"playerId"`
+	Name     string `json:"name"`
+	Bot      bool   `json:"bot"`
+	// nothing is waiting on the seat, it has picked from every pack passed to it
+	Picked bool `json:"picked"`
+	// packs waiting on the seat, including the one in front of it
+	Waiting   int  `json:"waiting"`
+	PoolSize  int  `json:"poolSize"`
+	Connected bool `json:"connected"`
+	// the seat this seat's packs go to in the current pack's direction
+	PassTo int `json:"passTo"`
+}
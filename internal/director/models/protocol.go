@@ -0,0 +1,171 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ProtocolVersion is the version of the envelope and payloads the server
+// speaks, it goes up whenever a payload changes in a way older clients
+// cannot read.
+//
+//	1: the first versioned envelope
+//	2: choose_card names the card by UUID with its pack number and round
+//	3: packs pass as soon as they are picked from, round is the pick number
+//	   of each pack rather than of the whole table
+const ProtocolVersion = 3
+
+// SupportedProtocolVersions are the versions clients may speak. Version 2
+// picks echo the round of the round_content they answer so they read as
+// version 3 ones. A version 1 pick is only an index into whatever pack is in
+// front of the player, a repeated one can't be told from the next pick, so
+// version 1 clients are turned away at hello.
+var SupportedProtocolVersions = []int{2, ProtocolVersion}
+
+func IsSupportedProtocolVersion(version int) bool {
+	for _, supported := range SupportedProtocolVersions {
+		if version == supported {
+			return true
+		}
+	}
+	return false
+}
+
+// HelloJson opens the handshake, a client lists every protocol version it
+// can speak
+type HelloJson struct {
+	Versions []int  `json:"versions"`
+	Client   string `json:"client"`
+}
+
+// WelcomeJson answers hello with the version the server picked
+type WelcomeJson struct {
+	Version  int    `json:"version"`
+	ClientId string `json:"clientId"`
+}
+
+type ErrorCode string
+
+const (
+	// the message is not a JSON envelope
+	ErrMalformedMessage ErrorCode = "malformed_message"
+	// the message or hello asked for a protocol version the server does not speak
+	ErrUnsupportedVersion ErrorCode = "unsupported_version"
+	// the type is not one clients can send
+	ErrUnknownMessageType ErrorCode = "unknown_message_type"
+	// the data does not fit the type's payload
+	ErrInvalidPayload ErrorCode = "invalid_payload"
+	// only the host can send the message
+	ErrUnauthorized ErrorCode = "unauthorized"
+	// the message does not apply to the game's current phase
+	ErrWrongPhase ErrorCode = "wrong_phase"
+	// the message was well formed but the game turned it down
+	ErrRejected ErrorCode = "rejected"
+	// a pick for a pack the player has already moved on from
+	ErrStalePick ErrorCode = "stale_pick"
+	// a second pick from the same pack, ie: a double click
+	ErrDuplicatePick ErrorCode = "duplicate_pick"
+	// the client is sending chat faster than the server lets it
+	ErrRateLimited ErrorCode = "rate_limited"
+	// the host muted the client
+	ErrMuted ErrorCode = "muted"
+)
+
+// ErrorJson is the payload of an error reply, Type is the message that
+// caused it when there is one
+type ErrorJson struct {
+	Code    ErrorCode       `json:"code"`
+	Message string          `json:"message"`
+	Type    GameMessageType `json:"type,omitempty"`
+}
+
+// ChatMessageJson is a chat line, clients only send Text and the server
+// stamps the rest before it goes out
+type ChatMessageJson struct {
+	Id         int       `json:"id,omitempty"`
+	SenderId   string    `json:"senderId,omitempty"`
+	SenderName string    `json:"senderName,omitempty"`
+	Text       string    `json:"text"`
+	SentAt     time.Time `json:"sentAt"`
+}
+
+// MessageSpec describes one message type, who can send it and the payload
+// its data holds
+type MessageSpec struct {
+	FromClient bool
+	FromServer bool
+	// Payload makes a new value for the message's data, nil for messages
+	// without data
+	Payload func() interface{}
+	// OptionalPayload messages may leave their data out
+	OptionalPayload bool
+}
+
+var messageSpecs = map[GameMessageType]MessageSpec{
+	Hello:             {FromClient: true, Payload: func() interface{} { return &HelloJson{} }},
+	Welcome:           {FromServer: true, Payload: func() interface{} { return &WelcomeJson{} }},
+	Error:             {FromServer: true, Payload: func() interface{} { return &ErrorJson{} }},
+	NewPlayer:         {FromServer: true, Payload: func() interface{} { return new(int) }},
+	ChatMessage:       {FromClient: true, FromServer: true, Payload: func() interface{} { return &ChatMessageJson{} }},
+	HostChange:        {FromServer: true, Payload: func() interface{} { return new(int) }},
+	GameStart:         {FromClient: true, FromServer: true, Payload: func() interface{} { return &TimerSettings{} }, OptionalPayload: true},
+	GameEnd:           {FromServer: true, Payload: func() interface{} { return new(int) }},
+	RoundContent:      {FromServer: true, Payload: func() interface{} { return &CardPack{} }},
+	PoolContent:       {FromServer: true, Payload: func() interface{} { return &[]SetCard{} }},
+	ChooseCard:        {FromClient: true, Payload: func() interface{} { return &ChooseCardJson{} }},
+	PickConfirmed:     {FromServer: true, Payload: func() interface{} { return &PickConfirmedJson{} }},
+	PhaseChange:       {FromServer: true, Payload: func() interface{} { return new(GamePhase) }},
+	SpectatorView:     {FromServer: true, Payload: func() interface{} { return &SpectatorViewJson{} }},
+	PickStatus:        {FromServer: true, Payload: func() interface{} { return &PickStatusJson{} }},
+	DeckContent:       {FromServer: true, Payload: func() interface{} { return &Deck{} }},
+	MoveCard:          {FromClient: true, Payload: func() interface{} { return &MoveCardJson{} }},
+	SetBasicLands:     {FromClient: true, Payload: func() interface{} { return &map[string]int{} }},
+	DeckReady:         {FromClient: true, Payload: func() interface{} { return &DeckReadyJson{} }},
+	DeckbuildingTimer: {FromClient: true, FromServer: true, Payload: func() interface{} { return &DeckbuildingTimerJson{} }},
+	DecksLocked:       {FromServer: true, Payload: func() interface{} { return new(int) }},
+	Roster:            {FromServer: true, Payload: func() interface{} { return &RosterJson{} }},
+	SetName:           {FromClient: true, Payload: func() interface{} { return &SetNameJson{} }},
+	SetReady:          {FromClient: true, Payload: func() interface{} { return &SetReadyJson{} }},
+	RandomizeSeats:    {FromClient: true},
+	ArrangeSeats:      {FromClient: true, Payload: func() interface{} { return &ArrangeSeatsJson{} }},
+	KickPlayer:        {FromClient: true, Payload: func() interface{} { return &KickPlayerJson{} }},
+	Kicked:            {FromServer: true, Payload: func() interface{} { return new(string) }},
+	PauseTimer:        {FromClient: true},
+	ResumeTimer:       {FromClient: true},
+	ExtendRound:       {FromClient: true, Payload: func() interface{} { return &ExtendRoundJson{} }},
+	ForceAdvance:      {FromClient: true},
+	EndGameEarly:      {FromClient: true},
+	MutePlayer:        {FromClient: true, Payload: func() interface{} { return &MutePlayerJson{} }},
+	RetractChat:       {FromClient: true, Payload: func() interface{} { return &RetractChatJson{} }},
+	ChatRetracted:     {FromServer: true, Payload: func() interface{} { return &RetractChatJson{} }},
+	RoundTimer:        {FromServer: true, Payload: func() interface{} { return &RoundTimerJson{} }},
+	Pairings:          {FromServer: true, Payload: func() interface{} { return &PairingsJson{} }},
+	MatchResult:       {FromClient: true, Payload: func() interface{} { return &MatchResultJson{} }},
+	MatchUpdate:       {FromServer: true, Payload: func() interface{} { return &MatchJson{} }},
+	Standings:         {FromServer: true, Payload: func() interface{} { return &StandingsJson{} }},
+}
+
+func GetMessageSpec(msgType GameMessageType) (MessageSpec, bool) {
+	spec, ok := messageSpecs[msgType]
+	return spec, ok
+}
+
+// DecodePayload unmarshals the message's data into the type's payload, it is
+// nil for messages without data
+func (spec MessageSpec) DecodePayload(msg *Message) (interface{}, error) {
+	if spec.Payload == nil {
+		return nil, nil
+	}
+	payload := spec.Payload()
+	if len(msg.Data) == 0 || string(msg.Data) == "null" {
+		if spec.OptionalPayload {
+			return payload, nil
+		}
+		return nil, errors.New(fmt.Sprintf("%s message has no data", msg.Type))
+	}
+	if err := msg.Decode(payload); err != nil {
+		return nil, errors.New(fmt.Sprintf("invalid %s data: %v", msg.Type, err))
+	}
+	return payload, nil
+}
@@ -35,6 +35,7 @@ type SetCard struct {
 		Vintage   string `json:"vintage"`
 	} `json:"legalities"`
 	ManaCost     string   `json:"manaCost"`
+	MtgoID       int      `json:"mtgoId"`
 	Name         string   `json:"name"`
 	Names        []string `json:"names"`
 	Number       string   `json:"number"`
@@ -58,7 +59,11 @@ type SetCard struct {
 		Date string `json:"date"`
 		Text string `json:"text"`
 	} `json:"rulings"`
-	ScryfallID             string        `json:"scryfallId"`
+	ScryfallID string `json:"scryfallId"`
+	// set the card was opened from, stamped by godr4ft when the source leaves it out
+	SetCode string `json:"setCode"`
+	// opened from a foil slot
+	Foil                   bool          `json:"foil"`
 	ScryfallIllustrationID string        `json:"scryfallIllustrationId"`
 	ScryfallOracleID       string        `json:"scryfallOracleId"`
 	Side                   string        `json:"side"`
@@ -70,4 +75,4 @@ type SetCard struct {
 	Types                  []string      `json:"types"`
 	UUID                   string        `json:"uuid"`
 	Variations             []string      `json:"variations"`
-}
\ No newline at end of file
+}
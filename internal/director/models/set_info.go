@@ -0,0 +1,8 @@
+package models
+
+type SetInfo struct {
+	Code        string `json:"code"`
+	Name        string `json:"name"`
+	ReleaseDate string `json:"releaseDate"`
+	Type        string `json:"type"`
+}
@@ -0,0 +1,19 @@
+package models
+
+// SpectatorSeatJson is what one seat holds, Pack is empty while the seat
+// waits for the next pack. Pick is the pick number of Pack and Queued the
+// packs waiting behind it.
+type SpectatorSeatJson struct {
+	Seat     int       `json:"seat"`
+	PlayerId string    `json:"playerId"`
+	IsBot    bool      `json:"isBot"`
+	Pack     []SetCard `json:"pack"`
+	Pick     int       `json:"pick"`
+	Queued   int       `json:"queued"`
+	Picks    []SetCard `json:"picks"`
+}
+
+type SpectatorViewJson struct {
+	PackNumber int                 `json:"packNumber"`
+	Seats      []SpectatorSeatJson `json:"seats"`
+}
@@ -0,0 +1,35 @@
+package models
+
+// GameStatusJson is a game at a glance, for pages that show live games
+// without joining them over a websocket
+type GameStatusJson struct {
+	GameId         string    `json:"gameId"`
+	Type           string    `json:"gameType"`
+	Mode           string    `json:"gameMode"`
+	Correspondence bool      `json:"correspondence"`
+	Phase          GamePhase `json:"phase"`
+	PackNumber     int       `json:"packNumber"`
+	TotalPacks     int       `json:"totalPacks"`
+	// the pick the slowest seat is on, packs pass as soon as they are picked from
+	Round int `json:"round"`
+	// seconds until the next forced pick while drafting or until decks lock
+	// while deckbuilding, 0 without a timer
+	Timer       int                    `json:"timer"`
+	TimerPaused bool                   `json:"timerPaused"`
+	Players     []GameStatusPlayerJson `json:"players"`
+	// everyone holding a pack, the draft is waiting on them
+	OwesPick []string `json:"owesPick"`
+}
+
+type GameStatusPlayerJson struct {
+	Id        string `json:"id"`
+	Name      string `json:"name"`
+	Seat      int    `json:"seat"`
+	Bot       bool   `json:"bot"`
+	Connected bool   `json:"connected"`
+	// pick of the pack in front of them, 0 while they wait for one
+	Pick   int `json:"pick"`
+	Queued int `json:"queued"`
+	// seconds left on their pick
+	Timer int `json:"timer"`
+}
@@ -0,0 +1,47 @@
+package models
+
+type PairingsJson struct {
+	Round       int          `json:"round"`
+	TotalRounds int          `json:"totalRounds"`
+	Matches     []*MatchJson `json:"matches"`
+}
+
+// MatchJson is a match as players see it, PlayerB is "bye" when PlayerA has
+// no opponent this round
+type MatchJson struct {
+	Round   int    `json:"round"`
+	Table   int    `json:"table"`
+	PlayerA string `json:"playerA"`
+	PlayerB string `json:"playerB"`
+	// results as each player reported them, from their own side
+	Reports map[string]MatchResultJson `json:"reports"`
+	// PlayerA's result once both players agree
+	Result    *MatchResultJson `json:"result"`
+	Confirmed bool             `json:"confirmed"`
+}
+
+type StandingsJson struct {
+	Round     int            `json:"round"`
+	Final     bool           `json:"final"`
+	Standings []StandingJson `json:"standings"`
+}
+
+type StandingJson struct {
+	Rank           int     `json:"rank"`
+	PlayerId       string  `json:"playerId"`
+	MatchPoints    int     `json:"matchPoints"`
+	MatchesPlayed  int     `json:"matchesPlayed"`
+	GamePoints     int     `json:"gamePoints"`
+	GamesPlayed    int     `json:"gamesPlayed"`
+	MatchWinPct    float64 `json:"matchWinPercentage"`
+	GameWinPct     float64 `json:"gameWinPercentage"`
+	OppMatchWinPct float64 `json:"opponentsMatchWinPercentage"`
+	OppGameWinPct  float64 `json:"opponentsGameWinPercentage"`
+}
+
+// MatchResultJson is a player's match result from their own side, ie: 2-1
+type MatchResultJson struct {
+	Wins   int `json:"wins"`
+	Losses int `json:"losses"`
+	Draws  int `json:"draws"`
+}
@@ -0,0 +1,197 @@
+package director
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/malexanderboyd/pwr9-godr4ft/internal/director/models"
+	"github.com/malexanderboyd/pwr9-godr4ft/internal/game"
+	"io/ioutil"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// inside an MTGJSON card source's directory games/ holds game options, ie:
+// games/a1b2c3d4.json, and boosters/ optional booster templates, ie: boosters/M20.json
+const (
+	MTGJSONGamesDir    = "games"
+	MTGJSONBoostersDir = "boosters"
+)
+
+type mtgjsonCard struct {
+	models.SetCard
+	// MTGJSON v5 moved ids under identifiers, and as strings
+	Identifiers struct {
+		MtgoID     string `json:"mtgoId"`
+		ScryfallID string `json:"scryfallId"`
+	} `json:"identifiers"`
+}
+
+type mtgjsonSet struct {
+	Code        string        `json:"code"`
+	Name        string        `json:"name"`
+	ReleaseDate string        `json:"releaseDate"`
+	Type        string        `json:"type"`
+	Cards       []mtgjsonCard `json:"cards"`
+}
+
+// MTGJSON v5 set files wrap the set in data, v4 files are the bare set
+type mtgjsonSetFile struct {
+	Data *mtgjsonSet `json:"data"`
+}
+
+// MTGJSONCardSource reads MTGJSON set files, ie: M20.json, from Dir and opens
+// boosters from them itself so a draft needs no companion API service.
+type MTGJSONCardSource struct {
+	Dir        string
+	rng        *rand.Rand
+	sets       map[string][]models.SetCard
+	setList    []models.SetInfo
+	generators map[string]*BoosterGenerator
+	mu         sync.Mutex
+}
+
+func NewMTGJSONCardSource(dir string) *MTGJSONCardSource {
+	return &MTGJSONCardSource{
+		Dir:        dir,
+		rng:        rand.New(rand.NewSource(time.Now().UnixNano())),
+		sets:       make(map[string][]models.SetCard),
+		generators: make(map[string]*BoosterGenerator),
+	}
+}
+
+func (source *MTGJSONCardSource) GetGameOptions(gameId string) (game.GeneralOptions, error) {
+	var gameOptions game.GeneralOptions
+	contents, err := ioutil.ReadFile(filepath.Join(source.Dir, MTGJSONGamesDir, gameId+".json"))
+	if err != nil {
+		return gameOptions, err
+	}
+	if err := json.Unmarshal(contents, &gameOptions); err != nil {
+		return gameOptions, errors.New(fmt.Sprintf("cannot decode options for game %s: %s", gameId, err.Error()))
+	}
+	return gameOptions, nil
+}
+
+func readMTGJSONSet(path string) (*mtgjsonSet, error) {
+	contents, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var file mtgjsonSetFile
+	if err := json.Unmarshal(contents, &file); err != nil {
+		return nil, errors.New(fmt.Sprintf("cannot decode MTGJSON set %s: %s", path, err.Error()))
+	}
+	if file.Data != nil {
+		return file.Data, nil
+	}
+
+	var set mtgjsonSet
+	if err := json.Unmarshal(contents, &set); err != nil {
+		return nil, errors.New(fmt.Sprintf("cannot decode MTGJSON set %s: %s", path, err.Error()))
+	}
+	return &set, nil
+}
+
+// GetSets reads every set file once and keeps the list for later games
+func (source *MTGJSONCardSource) GetSets() ([]models.SetInfo, error) {
+	source.mu.Lock()
+	defer source.mu.Unlock()
+	if source.setList != nil {
+		return source.setList, nil
+	}
+
+	paths, err := filepath.Glob(filepath.Join(source.Dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	var sets []models.SetInfo
+	for _, path := range paths {
+		set, err := readMTGJSONSet(path)
+		if err != nil {
+			return nil, err
+		}
+		sets = append(sets, models.SetInfo{
+			Code:        set.Code,
+			Name:        set.Name,
+			ReleaseDate: set.ReleaseDate,
+			Type:        set.Type,
+		})
+	}
+	source.setList = sets
+	return sets, nil
+}
+
+// getSetCards loads a set's cards once and keeps them for later boosters
+func (source *MTGJSONCardSource) getSetCards(setAbbrev string) ([]models.SetCard, error) {
+	if cards, ok := source.sets[setAbbrev]; ok {
+		return cards, nil
+	}
+
+	set, err := readMTGJSONSet(filepath.Join(source.Dir, strings.ToUpper(setAbbrev)+".json"))
+	if err != nil {
+		return nil, err
+	}
+
+	var cards []models.SetCard
+	for _, c := range set.Cards {
+		card := c.SetCard
+		if id, err := strconv.Atoi(c.Identifiers.MtgoID); err == nil && card.MtgoID == 0 {
+			card.MtgoID = id
+		}
+		if card.ScryfallID == "" {
+			card.ScryfallID = c.Identifiers.ScryfallID
+		}
+		if card.SetCode == "" {
+			card.SetCode = set.Code
+		}
+		cards = append(cards, card)
+	}
+	source.sets[setAbbrev] = cards
+	return cards, nil
+}
+
+// getBoosterTemplate uses boosters/{set}.json when the directory has one,
+// otherwise the set's built in template.
+func (source *MTGJSONCardSource) getBoosterTemplate(setAbbrev string) (BoosterTemplate, error) {
+	contents, err := ioutil.ReadFile(filepath.Join(source.Dir, MTGJSONBoostersDir, strings.ToUpper(setAbbrev)+".json"))
+	if os.IsNotExist(err) {
+		return GetBoosterTemplate(setAbbrev), nil
+	} else if err != nil {
+		return BoosterTemplate{}, err
+	}
+
+	var template BoosterTemplate
+	if err := json.Unmarshal(contents, &template); err != nil {
+		return template, errors.New(fmt.Sprintf("cannot decode %s booster template: %s", setAbbrev, err.Error()))
+	}
+	return template, nil
+}
+
+func (source *MTGJSONCardSource) GetBoosters(setAbbrev string, n int) (models.SetPacks, error) {
+	source.mu.Lock()
+	defer source.mu.Unlock()
+
+	generator, ok := source.generators[setAbbrev]
+	if !ok {
+		cards, err := source.getSetCards(setAbbrev)
+		if err != nil {
+			return models.SetPacks{}, err
+		}
+		template, err := source.getBoosterTemplate(setAbbrev)
+		if err != nil {
+			return models.SetPacks{}, err
+		}
+		if generator, err = NewBoosterGenerator(setAbbrev, cards, template, source.rng); err != nil {
+			return models.SetPacks{}, err
+		}
+		source.generators[setAbbrev] = generator
+	}
+	return generator.OpenBoosters(n), nil
+}
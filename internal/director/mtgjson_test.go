@@ -0,0 +1,167 @@
+package director_test
+
+import (
+	"fmt"
+	"github.com/malexanderboyd/pwr9-godr4ft/internal/director"
+	"github.com/malexanderboyd/pwr9-godr4ft/internal/game"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeMTGJSONSet(t *testing.T, dir, code string, wrapped bool) {
+	var cards []string
+	for i := 0; i < 12; i++ {
+		cards = append(cards, fmt.Sprintf(`{"name": "%s Common %d", "rarity": "common", "uuid": "%s-c%d"}`, code, i, code, i))
+	}
+	for i := 0; i < 4; i++ {
+		cards = append(cards, fmt.Sprintf(`{"name": "%s Uncommon %d", "rarity": "uncommon", "uuid": "%s-u%d", "identifiers": {"mtgoId": "%d"}}`, code, i, code, i, 100+i))
+	}
+	cards = append(cards,
+		fmt.Sprintf(`{"name": "%s Rare", "rarity": "rare", "uuid": "%s-r"}`, code, code),
+		`{"name": "Forest", "rarity": "common", "supertypes": ["Basic"], "uuid": "forest"}`,
+		`{"name": "Back Face", "rarity": "common", "side": "b", "uuid": "back"}`,
+	)
+	set := fmt.Sprintf(`{"code": "%s", "name": "Set %s", "releaseDate": "2019-07-12", "type": "core", "cards": [%s]}`, code, code, strings.Join(cards, ","))
+	if wrapped {
+		set = fmt.Sprintf(`{"meta": {}, "data": %s}`, set)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, code+".json"), []byte(set), 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestMTGJSONCardSource(t *testing.T) {
+	dir, err := ioutil.TempDir("", "mtgjson")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	writeMTGJSONSet(t, dir, "M20", true)
+	writeMTGJSONSet(t, dir, "LEA", false)
+
+	source := director.NewMTGJSONCardSource(dir)
+	sets, err := source.GetSets()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sets) != 2 {
+		t.Errorf("expected 2 sets, got %v", sets)
+	}
+	writeMTGJSONSet(t, dir, "M19", false)
+	if sets, err := source.GetSets(); err != nil || len(sets) != 2 {
+		t.Errorf("expected the set list to be read once, got %v %v", sets, err)
+	}
+
+	boosters, err := source.GetBoosters("M20", 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(boosters.Packs) != 3 {
+		t.Fatalf("expected 3 boosters, got %d", len(boosters.Packs))
+	}
+	for _, pack := range boosters.Packs {
+		if len(pack) != 15 {
+			t.Errorf("expected 15 card boosters, got %d", len(pack))
+		}
+		seen := make(map[string]bool)
+		for _, card := range pack {
+			if card.Name == "Back Face" || (card.Name == "Forest" && card.UUID != pack[len(pack)-1].UUID) {
+				t.Errorf("unexpected %s in a booster", card.Name)
+			}
+			if seen[card.UUID] {
+				t.Errorf("%s opened twice in one booster", card.Name)
+			}
+			seen[card.UUID] = true
+			if card.SetCode != "M20" {
+				t.Errorf("expected %s to be stamped with its set, got %q", card.Name, card.SetCode)
+			}
+			if card.Rarity == "uncommon" && card.MtgoID < 100 {
+				t.Errorf("expected the MTGO id from identifiers, got %d", card.MtgoID)
+			}
+		}
+		if pack[0].Rarity != "rare" {
+			t.Errorf("expected the rare slot first, got %s", pack[0].Rarity)
+		}
+		if pack[len(pack)-1].Name != "Forest" {
+			t.Errorf("expected the land slot last, got %s", pack[len(pack)-1].Name)
+		}
+	}
+
+	if _, err := source.GetBoosters("XYZ", 1); err == nil {
+		t.Errorf("expected an error for a set with no file")
+	}
+
+	if err := os.Mkdir(filepath.Join(dir, director.MTGJSONBoostersDir), 0700); err != nil {
+		t.Fatal(err)
+	}
+	template := `{"sheets": {"common": {"rarities": ["common"], "excludeSupertypes": ["Basic"]}}, "slots": [{"name": "common", "count": 2, "options": [{"sheet": "common", "weight": 1}]}]}`
+	if err := ioutil.WriteFile(filepath.Join(dir, director.MTGJSONBoostersDir, "LEA.json"), []byte(template), 0600); err != nil {
+		t.Fatal(err)
+	}
+	boosters, err = source.GetBoosters("LEA", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(boosters.Packs[0]) != 2 {
+		t.Errorf("expected the LEA template from the boosters directory, got %d cards", len(boosters.Packs[0]))
+	}
+
+	if err := os.Mkdir(filepath.Join(dir, director.MTGJSONGamesDir), 0700); err != nil {
+		t.Fatal(err)
+	}
+	options := `{"totalPlayers": 2, "gameType": 1, "gameMode": 1, "options": {"1": {"1": {"totalPacks": 2, "selectedPacks": {"0": "M20", "1": "LEA"}}}}}`
+	if err := ioutil.WriteFile(filepath.Join(dir, director.MTGJSONGamesDir, "lan_game.json"), []byte(options), 0600); err != nil {
+		t.Fatal(err)
+	}
+	gameOptions, err := source.GetGameOptions("lan_game")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gameOptions.TotalPlayers != 2 || gameOptions.Type != game.DRAFT || gameOptions.GameOptions.Draft.Regular.TotalPacks != 2 {
+		t.Fatalf("unexpected options %+v", gameOptions)
+	}
+
+	d := director.NewGameDirector(gameOptions, 9000, "lan_game")
+	d.SetCardSource(source)
+	if err := d.GetGameResources(); err != nil {
+		t.Fatal(err)
+	}
+	rounds := d.RoundPacks()
+	if len(rounds) != 2 || rounds[1].SetAbbreviation != "LEA" || len(rounds[1].PlayerPacks) != 2 {
+		t.Errorf("expected an M20 then LEA draft for 2 players, got %+v", rounds)
+	}
+	if len(rounds[1].PlayerPacks[0]) != 2 {
+		t.Errorf("expected LEA packs opened from the template, got %d cards", len(rounds[1].PlayerPacks[0]))
+	}
+}
+
+func TestHTTPCardSourceFallsBackToGeneratedBoosters(t *testing.T) {
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.NotFound(w, r)
+	}))
+	defer api.Close()
+	dir, err := ioutil.TempDir("", "mtgjson")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	writeMTGJSONSet(t, dir, "M20", false)
+
+	source := director.NewHTTPCardSource(api.URL)
+	if _, err := source.GetBoosters("M20", 1); err == nil {
+		t.Errorf("expected an error for a set the API has no boosters for")
+	}
+	source.Fallback = director.NewMTGJSONCardSource(dir)
+	boosters, err := source.GetBoosters("M20", 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(boosters.Packs) != 2 || len(boosters.Packs[0]) != 15 {
+		t.Errorf("expected 2 generated 15 card boosters, got %d", len(boosters.Packs))
+	}
+}
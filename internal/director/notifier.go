@@ -0,0 +1,89 @@
+package director
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/malexanderboyd/pwr9-godr4ft/internal"
+	"github.com/malexanderboyd/pwr9-godr4ft/internal/director/models"
+	"net/http"
+	"time"
+)
+
+// Notifier lets correspondence drafters know a pack is waiting for them, they
+// aren't expected to keep the draft open between picks
+type Notifier interface {
+	NotifyPackWaiting(notice *models.PackWaitingNotice) error
+}
+
+// LogNotifier only logs notices, it is the default until players have a way
+// to be reached
+type LogNotifier struct{}
+
+func (notifier *LogNotifier) NotifyPackWaiting(notice *models.PackWaitingNotice) error {
+	internal.GetLogger().Infow("Pack waiting", "game", notice.GameId, "player", notice.PlayerId,
+		"seat", notice.Seat, "pack_number", notice.PackNumber, "pick", notice.Pick, "deadline", notice.Deadline)
+	return nil
+}
+
+// WebhookNotifier posts each notice as JSON to Url
+type WebhookNotifier struct {
+	Url    string
+	Client *http.Client
+}
+
+func NewWebhookNotifier(url string) *WebhookNotifier {
+	return &WebhookNotifier{
+		Url:    url,
+		Client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func (notifier *WebhookNotifier) NotifyPackWaiting(notice *models.PackWaitingNotice) error {
+	body, err := json.Marshal(notice)
+	if err != nil {
+		return err
+	}
+	res, err := notifier.Client.Post(notifier.Url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return errors.New(fmt.Sprintf("webhook %s answered %s", notifier.Url, res.Status))
+	}
+	return nil
+}
+
+// notifyPackWaiting tells a correspondence drafter who isn't connected that
+// the pack at the head of their queue is waiting, callers hold pickMu
+func (director *GameDirector) notifyPackWaiting(seat int) {
+	if director.notifier == nil || !director.isCorrespondence() {
+		return
+	}
+	clientID := director.getClientIdBySeat(seat)
+	client, ok := director.Clients[clientID]
+	head := director.getHeadPack(seat)
+	if !ok || client.IsConnected() || head == nil {
+		// bots and anyone looking at the draft don't need telling
+		return
+	}
+
+	notice := &models.PackWaitingNotice{
+		GameId:     director.GameId,
+		PlayerId:   clientID,
+		Name:       client.getName(),
+		Seat:       seat,
+		PackNumber: director.packNumber + 1,
+		Pick:       head.Pick,
+		Deadline:   director.getPickDeadline(seat),
+	}
+	notifier := director.notifier
+	go func() {
+		if err := notifier.NotifyPackWaiting(notice); err != nil {
+			internal.GetLogger().Errorw("cannot notify player", "game", notice.GameId, "player", notice.PlayerId, "error", err.Error())
+		}
+	}()
+}
@@ -0,0 +1,46 @@
+package director
+
+import (
+	"github.com/malexanderboyd/pwr9-godr4ft/internal/director/models"
+)
+
+// getPickStatusMessage lists every seat in seat order with whether the draft
+// is waiting on it, callers hold pickMu
+func (director *GameDirector) getPickStatusMessage() *models.Message {
+	status := models.PickStatusJson{
+		PackNumber: director.packNumber + 1,
+	}
+	for _, player := range director.getRoster().Players {
+		if player.Seat < 0 {
+			continue
+		}
+		waiting := len(director.packQueues[player.Seat])
+		seat := models.PickStatusSeatJson{
+			Seat:      player.Seat,
+			PlayerId:  player.Id,
+			Name:      player.Name,
+			Bot:       player.Bot,
+			Picked:    waiting == 0,
+			Waiting:   waiting,
+			Connected: player.Connected,
+			PassTo:    director.getSeatNumberForNextRound(player.Seat),
+		}
+		if client, ok := director.Clients[player.Id]; ok {
+			seat.PoolSize = client.getPoolSize()
+		} else if bot, ok := director.Bots[player.Id]; ok {
+			seat.PoolSize = len(bot.pool)
+		}
+		status.Seats = append(status.Seats, seat)
+	}
+	return models.NewMessage(models.PickStatus, &status)
+}
+
+// sendPickStatus shows every player who the draft is waiting on. Picks are
+// taken on the Listen loop, which owns Clients and Bots, so it writes to
+// players directly rather than through SendAll. Callers hold pickMu.
+func (director *GameDirector) sendPickStatus() {
+	msg := director.getPickStatusMessage()
+	for _, client := range director.Clients {
+		client.Write(msg)
+	}
+}
@@ -0,0 +1,37 @@
+package director_test
+
+import (
+	"github.com/malexanderboyd/pwr9-godr4ft/internal/director"
+	"github.com/malexanderboyd/pwr9-godr4ft/internal/director/models"
+	"github.com/malexanderboyd/pwr9-godr4ft/internal/game"
+	"testing"
+	"time"
+)
+
+func TestPickStatusAfterEveryPick(t *testing.T) {
+	d, clients := director.NewSeatedCubeDraft(t, "pick_status_game", game.GeneralOptions{}, 1)
+	client := clients[0]
+	d.DealPack()
+
+	var status models.PickStatusJson
+	if msg := director.NextMessageOfType(t, client, models.PickStatus, 0); msg == nil {
+		t.Fatalf("expected the pick status once the pack is dealt")
+	} else {
+		_ = msg.Decode(&status)
+	}
+	if len(status.Seats) != 2 || status.Seats[0].Picked || status.Seats[1].Picked {
+		t.Fatalf("expected both seats to owe a pick, got %+v", status.Seats)
+	}
+
+	if err := d.ChooseCard(client.Id, 0, false); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	_ = director.NextMessageOfType(t, client, models.PickStatus, time.Second).Decode(&status)
+	player, bot := status.Seats[0], status.Seats[1]
+	if player.PlayerId != client.Id || !player.Picked || player.PoolSize != 1 || player.PassTo != 1 || player.Name == "" {
+		t.Errorf("expected the player to have picked and pass to seat 1, got %+v", player)
+	}
+	if !bot.Bot || bot.Picked || bot.Waiting != 2 || !bot.Connected {
+		t.Errorf("expected the bot to have two packs waiting on it, got %+v", bot)
+	}
+}
@@ -0,0 +1,148 @@
+package director_test
+
+import (
+	"github.com/malexanderboyd/pwr9-godr4ft/internal/director"
+	"github.com/malexanderboyd/pwr9-godr4ft/internal/director/models"
+	"github.com/malexanderboyd/pwr9-godr4ft/internal/game"
+	"testing"
+)
+
+func TestPicksAreByUUIDAndSequence(t *testing.T) {
+	d, clients := director.NewSeatedCubeDraft(t, "pick_game", game.GeneralOptions{}, 1)
+	client := clients[0]
+	d.DealPack()
+
+	pack := d.RoundPacks()[0].PlayerPacks[0]
+	picked := pack[1]
+	pick := &models.ChooseCardJson{UUID: picked.UUID, PackNumber: 1, Round: 1}
+
+	stale := &models.ChooseCardJson{UUID: picked.UUID, PackNumber: 1, Round: 0}
+	d.HandleClientMessage(client.Id, models.NewMessage(models.ChooseCard, stale))
+	if code := decodeErrorCode(t, director.NextMessageOfType(t, client, models.Error, 0)); code != models.ErrStalePick {
+		t.Errorf("expected a stale pick, got %q", code)
+	}
+
+	missing := &models.ChooseCardJson{UUID: "not-a-card", PackNumber: 1, Round: 1}
+	d.HandleClientMessage(client.Id, models.NewMessage(models.ChooseCard, missing))
+	if code := decodeErrorCode(t, director.NextMessageOfType(t, client, models.Error, 0)); code != models.ErrRejected {
+		t.Errorf("expected a card outside the pack to be rejected, got %q", code)
+	}
+
+	d.HandleClientMessage(client.Id, models.NewMessage(models.ChooseCard, pick))
+	confirmed := director.NextMessageOfType(t, client, models.PickConfirmed, 0)
+	if confirmed == nil {
+		t.Fatalf("expected a pick_confirmed message")
+	}
+	var confirmation models.PickConfirmedJson
+	_ = confirmed.Decode(&confirmation)
+	if confirmation.Card.UUID != picked.UUID || confirmation.Forced {
+		t.Errorf("expected %s to be confirmed, got %+v", picked.UUID, confirmation)
+	}
+
+	d.HandleClientMessage(client.Id, models.NewMessage(models.ChooseCard, pick))
+	if code := decodeErrorCode(t, director.NextMessageOfType(t, client, models.Error, 0)); code != models.ErrDuplicatePick {
+		t.Errorf("expected a duplicate pick, got %q", code)
+	}
+	var clientPicks int
+	for _, event := range d.PickLog() {
+		if event.PlayerId == client.Id {
+			clientPicks++
+		}
+	}
+	if clientPicks != 1 {
+		t.Errorf("expected exactly one pick to be recorded, got %d", clientPicks)
+	}
+}
+
+func TestVersion2PicksEchoTheirRound(t *testing.T) {
+	d, clients := director.NewSeatedCubeDraft(t, "v2_pick_game", game.GeneralOptions{}, 1)
+	client := clients[0]
+	d.DealPack()
+
+	var pack models.CardPack
+	_ = director.NextMessageOfType(t, client, models.RoundContent, 0).Decode(&pack)
+	stale := models.NewMessage(models.ChooseCard, &models.ChooseCardJson{UUID: pack.Pack[0].UUID, PackNumber: 1, Round: 99})
+	stale.Version = 2
+	d.HandleClientMessage(client.Id, stale)
+	if code := decodeErrorCode(t, director.NextMessageOfType(t, client, models.Error, 0)); code != models.ErrStalePick {
+		t.Errorf("expected a v2 pick for another round to be stale, got %q", code)
+	}
+	v2 := models.NewMessage(models.ChooseCard, &models.ChooseCardJson{UUID: pack.Pack[0].UUID, PackNumber: pack.PackNumber, Round: pack.Round})
+	v2.Version = 2
+	d.HandleClientMessage(client.Id, v2)
+	var confirmation models.PickConfirmedJson
+	_ = director.NextMessageOfType(t, client, models.PickConfirmed, 0).Decode(&confirmation)
+	if confirmation.Card.UUID != pack.Pack[0].UUID {
+		t.Errorf("expected the v2 pick of %s, got %+v", pack.Pack[0].UUID, confirmation)
+	}
+	d.HandleClientMessage(client.Id, v2)
+	if code := decodeErrorCode(t, director.NextMessageOfType(t, client, models.Error, 0)); code != models.ErrDuplicatePick {
+		t.Errorf("expected a repeated v2 pick to be a duplicate, got %q", code)
+	}
+}
+
+func TestVersion1IsTurnedAway(t *testing.T) {
+	d, clients := director.NewSeatedCubeDraft(t, "v1_pick_game", game.GeneralOptions{}, 1)
+	client := clients[0]
+	d.DealPack()
+
+	d.HandleClientMessage(client.Id, models.NewMessage(models.Hello, &models.HelloJson{Versions: []int{1}}))
+	if code := decodeErrorCode(t, director.NextMessageOfType(t, client, models.Error, 0)); code != models.ErrUnsupportedVersion {
+		t.Errorf("expected a v1 hello to fail, got %q", code)
+	}
+	d.HandleClientMessage(client.Id, &models.Message{Version: 1, Type: models.ChooseCard, Data: []byte(`{"pickedCardIndex": 0}`)})
+	if code := decodeErrorCode(t, director.NextMessageOfType(t, client, models.Error, 0)); code != models.ErrUnsupportedVersion {
+		t.Errorf("expected a v1 pick to be turned away, got %q", code)
+	}
+	for _, event := range d.PickLog() {
+		if event.PlayerId == client.Id {
+			t.Errorf("expected no pick to be recorded")
+		}
+	}
+}
+
+func decodeErrorCode(t *testing.T, msg *models.Message) models.ErrorCode {
+	if msg == nil {
+		t.Fatalf("expected an error message")
+	}
+	var errMsg models.ErrorJson
+	if err := msg.Decode(&errMsg); err != nil {
+		t.Fatal(err)
+	}
+	return errMsg.Code
+}
+
+func TestPacksPassWithoutWaitingForTheTable(t *testing.T) {
+	d, clients := director.NewSeatedCubeDraft(t, "queue_game", game.GeneralOptions{}, 1)
+	client := clients[0]
+	d.DealPack()
+	var botID string
+	for id := range d.Bots {
+		botID = id
+	}
+
+	if err := d.ChooseCard(client.Id, 0, false); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	// the bot passes its pack on, the player's own pack is still queued behind it
+	if err := d.ChooseCard(botID, 0, false); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	var content models.CardPack
+	msg := director.NextMessageOfType(t, client, models.RoundContent, 0)
+	for next := director.NextMessageOfType(t, client, models.RoundContent, 0); next != nil; next = director.NextMessageOfType(t, client, models.RoundContent, 0) {
+		msg = next
+	}
+	if msg == nil {
+		t.Fatalf("expected the passed pack to be shown")
+	}
+	_ = msg.Decode(&content)
+	if content.Round != 2 || len(content.Pack) != 2 {
+		t.Fatalf("expected pick 2 of a two card pack, got pick %d of %d cards", content.Round, len(content.Pack))
+	}
+
+	if err := d.ChooseCard(client.Id, 0, false); err != nil {
+		t.Errorf("expected the player to pick ahead of the bot, got %v", err)
+	}
+}
@@ -0,0 +1,100 @@
+package director
+
+import (
+	"errors"
+	"fmt"
+	"github.com/malexanderboyd/pwr9-godr4ft/internal"
+	"github.com/malexanderboyd/pwr9-godr4ft/internal/director/models"
+)
+
+// clientError is an error the client caused, it goes back to them with its code
+type clientError struct {
+	code models.ErrorCode
+	err  error
+}
+
+func newClientError(code models.ErrorCode, format string, args ...interface{}) error {
+	return &clientError{code: code, err: errors.New(fmt.Sprintf(format, args...))}
+}
+
+func (e *clientError) Error() string {
+	return e.err.Error()
+}
+
+// getConnectedClient finds a player or spectator by id
+func (director *GameDirector) getConnectedClient(clientID string) *Client {
+	if client, ok := director.Clients[clientID]; ok {
+		return client
+	}
+	director.spectatorMu.Lock()
+	defer director.spectatorMu.Unlock()
+	return director.Spectators[clientID]
+}
+
+// writeError replies to the client that sent msgType with a machine readable
+// code alongside the reason
+func (director *GameDirector) writeError(clientID string, msgType models.GameMessageType, code models.ErrorCode, err error) {
+	internal.GetLogger().Debugw("client error", "client", clientID, "type", msgType, "code", code, "error", err.Error())
+	if client := director.getConnectedClient(clientID); client != nil {
+		client.Write(models.NewMessage(models.Error, &models.ErrorJson{
+			Code:    code,
+			Message: err.Error(),
+			Type:    msgType,
+		}))
+	}
+}
+
+// handleClientHello picks the newest protocol version both sides speak.
+// Clients that never say hello are assumed to speak the current version.
+func (director *GameDirector) handleClientHello(clientID string, hello *models.HelloJson) {
+	client := director.getConnectedClient(clientID)
+	if client == nil {
+		return
+	}
+
+	version := 0
+	for _, v := range hello.Versions {
+		if models.IsSupportedProtocolVersion(v) && v > version {
+			version = v
+		}
+	}
+	if version == 0 {
+		director.writeError(clientID, models.Hello, models.ErrUnsupportedVersion,
+			errors.New(fmt.Sprintf("none of the protocol versions %v are supported, the server speaks %v", hello.Versions, models.SupportedProtocolVersions)))
+		return
+	}
+
+	internal.GetLogger().Debugw("Client hello", "game", director.GameId, "client", clientID, "name", hello.Client, "version", version)
+	client.setProtocolVersion(version)
+	client.Write(models.NewMessage(models.Welcome, &models.WelcomeJson{
+		Version:  version,
+		ClientId: clientID,
+	}))
+}
+
+// decodeClientMessage checks a client's message against the protocol and
+// returns its payload, errors have already been sent back to the client
+func (director *GameDirector) decodeClientMessage(clientID string, msg *models.Message) (interface{}, bool) {
+	if msg.Version != 0 {
+		client := director.getConnectedClient(clientID)
+		if !models.IsSupportedProtocolVersion(msg.Version) || (client != nil && client.getProtocolVersion() != 0 && client.getProtocolVersion() != msg.Version) {
+			director.writeError(clientID, msg.Type, models.ErrUnsupportedVersion,
+				errors.New(fmt.Sprintf("protocol version %d was not negotiated", msg.Version)))
+			return nil, false
+		}
+	}
+
+	spec, ok := models.GetMessageSpec(msg.Type)
+	if !ok || !spec.FromClient {
+		director.writeError(clientID, msg.Type, models.ErrUnknownMessageType,
+			errors.New(fmt.Sprintf("clients cannot send %q messages", msg.Type)))
+		return nil, false
+	}
+
+	payload, err := spec.DecodePayload(msg)
+	if err != nil {
+		director.writeError(clientID, msg.Type, models.ErrInvalidPayload, err)
+		return nil, false
+	}
+	return payload, true
+}
@@ -0,0 +1,72 @@
+package director_test
+
+import (
+	"fmt"
+	"github.com/gorilla/websocket"
+	"github.com/malexanderboyd/pwr9-godr4ft/internal/director"
+	"github.com/malexanderboyd/pwr9-godr4ft/internal/director/models"
+	"github.com/malexanderboyd/pwr9-godr4ft/internal/game"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestProtocolHandshakeAndErrorCodes(t *testing.T) {
+	var options game.GeneralOptions
+	d := director.NewGameDirector(options, 9000, "protocol_game")
+	go d.Listen()
+	defer d.Finish()
+
+	ts := httptest.NewServer(d.Handler())
+	defer ts.Close()
+	ws, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(ts.URL, "http")+"/ws", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ws.Close()
+
+	_ = ws.WriteJSON(models.NewMessage(models.Hello, &models.HelloJson{Versions: []int{99}}))
+	if code := director.ReadError(t, ws); code != models.ErrUnsupportedVersion {
+		t.Errorf("expected an unsupported version error, got %q", code)
+	}
+
+	_ = ws.WriteJSON(models.NewMessage(models.Hello, &models.HelloJson{Versions: []int{models.ProtocolVersion, 99}, Client: "test"}))
+	var welcome models.WelcomeJson
+	if err := director.ReadUntil(t, ws, models.Welcome).Decode(&welcome); err != nil {
+		t.Fatal(err)
+	}
+	if welcome.Version != models.ProtocolVersion || welcome.ClientId == "" {
+		t.Errorf("expected to agree on version %d, got %+v", models.ProtocolVersion, welcome)
+	}
+
+	tests := []struct {
+		name string
+		raw  string
+		code models.ErrorCode
+	}{
+		{"not json", `{"type": "chat_message",`, models.ErrMalformedMessage},
+		{"unknown type", `{"v": %[1]d, "type": "launch_missiles"}`, models.ErrUnknownMessageType},
+		{"server only type", `{"v": %[1]d, "type": "round_content", "data": {}}`, models.ErrUnknownMessageType},
+		{"wrong payload", `{"v": %[1]d, "type": "set_name", "data": {"name": 7}}`, models.ErrInvalidPayload},
+		{"missing payload", `{"v": %[1]d, "type": "choose_card"}`, models.ErrInvalidPayload},
+		{"other version", `{"v": 99, "type": "set_name", "data": {"name": "x"}}`, models.ErrUnsupportedVersion},
+		{"wrong phase", `{"v": %[1]d, "type": "move_card", "data": {"uuid": "x"}}`, models.ErrWrongPhase},
+	}
+	for _, test := range tests {
+		raw := test.raw
+		if strings.Contains(raw, "%[1]d") {
+			raw = fmt.Sprintf(raw, models.ProtocolVersion)
+		}
+		_ = ws.WriteMessage(websocket.TextMessage, []byte(raw))
+		if code := director.ReadError(t, ws); code != test.code {
+			t.Errorf("%s: expected %q, got %q", test.name, test.code, code)
+		}
+	}
+
+	_ = ws.WriteJSON(models.NewMessage(models.ChatMessage, &models.ChatMessageJson{Text: "gl hf"}))
+	var chat models.ChatMessageJson
+	msg := director.ReadUntil(t, ws, models.ChatMessage)
+	if err := msg.Decode(&chat); err != nil || chat.Text != "gl hf" || msg.Version != models.ProtocolVersion {
+		t.Errorf("expected the chat message back in a versioned envelope, got %+v %v", msg, err)
+	}
+}
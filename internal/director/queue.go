@@ -0,0 +1,414 @@
+package director
+
+import (
+	"errors"
+	"fmt"
+	"github.com/malexanderboyd/pwr9-godr4ft/internal"
+	"github.com/malexanderboyd/pwr9-godr4ft/internal/director/models"
+	"sort"
+	"time"
+)
+
+// Packs pass like a paper draft, every seat has a queue of packs and a pick
+// sends that pack straight on to the next seat's queue. Nobody waits on the
+// rest of the table, only on the player passing to them. The queues, pick
+// counts and timers below are guarded by pickMu.
+
+// pickTimer runs for the pack at the head of a seat's queue, from when the
+// pack reached the seat
+type pickTimer struct {
+	shownAt   time.Time
+	elapsed   time.Duration
+	extension time.Duration
+}
+
+// dealPack opens the current pack number's boosters, every seat starts with
+// its own pack at the head of its queue
+func (director *GameDirector) dealPack() {
+	director.pickMu.Lock()
+	defer director.pickMu.Unlock()
+
+	round := director.roundPacks[director.packNumber]
+	director.packQueues = make(map[int][]*models.QueuedPack)
+	director.seatPicks = make(map[int]int)
+	director.pickTimers = make(map[int]*pickTimer)
+	for seat, pack := range round.PlayerPacks {
+		if len(pack) == 0 {
+			continue
+		}
+		director.packQueues[seat] = []*models.QueuedPack{{
+			SetName: round.GetPackSetName(seat),
+			Cards:   append([]models.SetCard{}, pack...),
+			Pick:    1,
+		}}
+		director.startPickTimer(seat)
+	}
+
+	for clientID, seat := range director.Seats {
+		director.writeRoundContent(clientID, seat)
+		director.notifyPackWaiting(seat)
+	}
+	director.sendPickStatus()
+	director.sendSpectatorView()
+}
+
+func (director *GameDirector) getHeadPack(seat int) *models.QueuedPack {
+	if queue := director.packQueues[seat]; len(queue) > 0 {
+		return queue[0]
+	}
+	return nil
+}
+
+// isPackOver is true once every card of the current pack number is picked
+func (director *GameDirector) isPackOver() bool {
+	for _, queue := range director.packQueues {
+		if len(queue) > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func (director *GameDirector) signalNextPack() {
+	select {
+	case director.startNextPackCh <- true:
+	case <-director.finishedCh:
+	}
+}
+
+// takePick takes the named card from the pack at the head of the drafter's
+// queue and passes the rest on. Picks for a pack the drafter is not holding
+// are stale and a pick they already made is a duplicate, both are turned
+// down without touching the pack. Callers hold pickMu.
+func (director *GameDirector) takePick(clientID string, selectedCardMsg *models.ChooseCardJson, forced bool) error {
+	drafter := director.getDrafter(clientID)
+	seat, seated := director.Seats[clientID]
+	if drafter == nil || !seated {
+		return errors.New(fmt.Sprintf("No seated client with id: %s. Must provide valid client ID", clientID))
+	}
+
+	if selectedCardMsg.PackNumber == director.packNumber+1 && selectedCardMsg.Round >= 1 && selectedCardMsg.Round <= director.seatPicks[seat] {
+		return newClientError(models.ErrDuplicatePick, "[client %s] already picked from pack %d pick %d",
+			clientID, selectedCardMsg.PackNumber, selectedCardMsg.Round)
+	}
+	head := director.getHeadPack(seat)
+	if head == nil || selectedCardMsg.PackNumber != director.packNumber+1 || selectedCardMsg.Round != head.Pick {
+		return newClientError(models.ErrStalePick, "pick %d of pack %d is not the pack in front of client %s",
+			selectedCardMsg.Round, selectedCardMsg.PackNumber, clientID)
+	}
+
+	chosenIndex := -1
+	for i, card := range head.Cards {
+		if card.UUID == selectedCardMsg.UUID {
+			chosenIndex = i
+			break
+		}
+	}
+	if chosenIndex < 0 {
+		return errors.New(fmt.Sprintf("[client %s] chose card %q which is not in their pack", clientID, selectedCardMsg.UUID))
+	}
+
+	chosenCard := head.Cards[chosenIndex]
+	shownAt := director.getPickTimer(seat).shownAt
+	director.recordPick(clientID, seat, head.Pick, head.Cards, chosenCard, forced, shownAt)
+	_, isBot := director.Bots[clientID]
+	observePick(seat, isBot, forced, shownAt)
+
+	// a new slice, round_content messages still being written hold the old one
+	rest := make([]models.SetCard, 0, len(head.Cards)-1)
+	rest = append(rest, head.Cards[:chosenIndex]...)
+	rest = append(rest, head.Cards[chosenIndex+1:]...)
+	director.packQueues[seat] = director.packQueues[seat][1:]
+	director.seatPicks[seat]++
+
+	drafter.AddCardToPool(chosenCard)
+	if player, ok := director.Clients[clientID]; ok {
+		player.Write(models.NewMessage(models.PickConfirmed, &models.PickConfirmedJson{
+			Card:       models.NewCardRef(chosenCard),
+			PackNumber: selectedCardMsg.PackNumber,
+			Round:      selectedCardMsg.Round,
+			Forced:     forced,
+		}))
+	}
+	drafter.WriteCurrentPool()
+
+	if director.getHeadPack(seat) != nil {
+		director.startPickTimer(seat)
+		director.writeRoundContent(clientID, seat)
+		director.notifyPackWaiting(seat)
+	}
+	if len(rest) > 0 {
+		nextSeat := director.getSeatNumberForNextRound(seat)
+		director.packQueues[nextSeat] = append(director.packQueues[nextSeat], &models.QueuedPack{
+			SetName: head.SetName,
+			Cards:   rest,
+			Pick:    head.Pick + 1,
+		})
+		if len(director.packQueues[nextSeat]) == 1 {
+			// the seat was waiting, it gets the pack right away
+			director.startPickTimer(nextSeat)
+			director.writeRoundContent(director.getClientIdBySeat(nextSeat), nextSeat)
+			director.notifyPackWaiting(nextSeat)
+		}
+	}
+	director.sendPickStatus()
+	director.sendSpectatorView()
+
+	if director.isPackOver() {
+		go director.signalNextPack()
+	}
+	return nil
+}
+
+// getPick is the pick of the card at index in the drafter's current pack, it
+// is how the server and bots pick
+func (director *GameDirector) getPick(clientID string, index int) *models.ChooseCardJson {
+	pick := &models.ChooseCardJson{
+		PackNumber: director.packNumber + 1,
+	}
+	if head := director.getHeadPack(director.getSeatByClientId(clientID)); head != nil {
+		pick.Round = head.Pick
+		if index >= 0 && index < len(head.Cards) {
+			pick.UUID = head.Cards[index].UUID
+		}
+	}
+	return pick
+}
+
+// forcePick picks the first card of the pack in front of seat for a player
+// who ran out of time
+func (director *GameDirector) forcePick(seat int) error {
+	director.pickMu.Lock()
+	defer director.pickMu.Unlock()
+	if director.getHeadPack(seat) == nil {
+		return nil
+	}
+	clientID := director.getClientIdBySeat(seat)
+	return director.takePick(clientID, director.getPick(clientID, 0), true)
+}
+
+// pickCardsForBots has bots pick until none of them holds a pack, a bot's
+// pick can pass a pack straight on to the next bot
+func (director *GameDirector) pickCardsForBots() {
+	logger := internal.GetLogger()
+	for picked := true; picked; {
+		picked = false
+		director.pickMu.Lock()
+		// a kicked player's seat can go to a new bot while this runs
+		bots := make(map[string]*Bot)
+		for botID, bot := range director.Bots {
+			bots[botID] = bot
+		}
+		director.pickMu.Unlock()
+		for botID, bot := range bots {
+			ok, err := director.pickCardForBot(botID, bot)
+			if err != nil {
+				logger.Errorw("bot cannot pick", "game", director.GameId, "bot", botID, "error", err.Error())
+			}
+			picked = picked || ok
+		}
+	}
+}
+
+// pickCardForBot has a bot pick from its current pack, if it holds one
+func (director *GameDirector) pickCardForBot(botID string, bot *Bot) (bool, error) {
+	director.pickMu.Lock()
+	defer director.pickMu.Unlock()
+	pack := director.getPackByClientID(botID)
+	if pack == nil {
+		return false, nil
+	}
+	if err := director.takePick(botID, director.getPick(botID, bot.PickCard(pack)), false); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (director *GameDirector) getRoundContentMessage(seat int) *models.Message {
+	head := director.getHeadPack(seat)
+	newPack := &models.CardPack{
+		SetName:    head.SetName,
+		Pack:       head.Cards,
+		Round:      head.Pick,
+		PackNumber: director.packNumber + 1,
+		Queued:     len(director.packQueues[seat]) - 1,
+	}
+
+	if director.isTimerEnabled() {
+		newPack.Timer = int(director.getPickTimeRemaining(seat) / time.Second)
+	}
+	if director.isCorrespondence() {
+		deadline := director.getPickDeadline(seat)
+		newPack.Deadline = &deadline
+	}
+
+	return models.NewMessage(models.RoundContent, newPack)
+}
+
+// writeRoundContent shows a player the pack at the head of their queue
+func (director *GameDirector) writeRoundContent(clientID string, seat int) {
+	client, ok := director.Clients[clientID]
+	if !ok || director.getHeadPack(seat) == nil {
+		return
+	}
+	client.Write(director.getRoundContentMessage(seat))
+	if director.isRoundTimerPaused() {
+		// round_content carries the full timer, players need to know it is held
+		client.Write(director.getRoundTimerMessage(seat))
+	}
+}
+
+// isCorrespondence is true for drafts played over days, every pick has hours
+// on the clock and the draft carries on with nobody connected
+func (director *GameDirector) isCorrespondence() bool {
+	return director.options.PickDeadlineHours > 0
+}
+
+func (director *GameDirector) getPickDuration(pick int) time.Duration {
+	if director.isCorrespondence() {
+		return time.Duration(director.options.PickDeadlineHours) * time.Hour
+	}
+	var roundTime = 1 * time.Second
+	switch director.roundTimerType {
+	case "leisurely":
+		//'Leisurely - Starts @ 90s and decrements by 5s per pick'
+		roundTime = 90*time.Second - (5 * time.Second * (time.Duration(pick - 1)))
+		break
+	case "slow":
+		//'Slow - Starts @ 75s and decrements by 5s per pick'
+		roundTime = 75*time.Second - (5 * time.Second * (time.Duration(pick - 1)))
+		break
+	case "moderate":
+		//'Moderate - Starts @ 55s A happy medium between slow, and fast.'
+		roundTime = 55*time.Second - (5 * time.Second * (time.Duration(pick - 1)))
+		break
+	case "fast":
+		//'Fast - Starts @ 40s, based on official WOTC timing'
+		roundTime = 40*time.Second - (5 * time.Second * (time.Duration(pick - 1)))
+		break
+	}
+
+	if roundTime < 3*time.Second {
+		roundTime = 3 * time.Second
+	}
+	return roundTime
+}
+
+// startPickTimer starts the clock on the pack that just reached the head of
+// the seat's queue
+func (director *GameDirector) startPickTimer(seat int) {
+	director.pickTimers[seat] = &pickTimer{shownAt: time.Now()}
+}
+
+func (director *GameDirector) getPickTimer(seat int) *pickTimer {
+	timer, ok := director.pickTimers[seat]
+	if !ok {
+		// restored from a snapshot without timers, they start over
+		director.startPickTimer(seat)
+		timer = director.pickTimers[seat]
+	}
+	return timer
+}
+
+func (director *GameDirector) getPickTimeRemaining(seat int) time.Duration {
+	head := director.getHeadPack(seat)
+	if head == nil {
+		return 0
+	}
+	timer := director.getPickTimer(seat)
+	elapsed := timer.elapsed
+	if director.isCorrespondence() {
+		// correspondence deadlines are on the wall clock, they keep running
+		// while the server is down
+		elapsed = time.Since(timer.shownAt)
+	}
+	remaining := director.getPickDuration(head.Pick) + timer.extension - elapsed
+	if remaining < 0 {
+		return 0
+	}
+	return remaining
+}
+
+// getPickDeadline is when the server picks for the seat, callers hold pickMu
+func (director *GameDirector) getPickDeadline(seat int) time.Time {
+	return time.Now().Add(director.getPickTimeRemaining(seat)).Truncate(time.Second)
+}
+
+// PickSnapshotDelay is how long picks wait to be snapshotted, a snapshot
+// covers every pick made meanwhile instead of rewriting the file per pick
+const PickSnapshotDelay = 5 * time.Second
+
+// savePicks snapshots the draft after picks, correspondence drafts save right
+// away since a pick there can be a day's work. It runs on the Listen loop.
+func (director *GameDirector) savePicks() {
+	if director.isCorrespondence() {
+		director.saveSnapshot()
+		return
+	}
+	if director.snapshotPending {
+		return
+	}
+	director.snapshotPending = true
+	time.AfterFunc(director.pickSnapshotDelay, func() {
+		director.runOnLoop(func() {
+			director.snapshotPending = false
+			if director.phase != models.PhaseEnded {
+				director.saveSnapshot()
+			}
+		})
+	})
+}
+
+// startPickTicker counts down the pick timer of every seat holding a pack and
+// picks for anyone who runs out of time, it runs until the draft is over
+func (director *GameDirector) startPickTicker() {
+	ticker := time.NewTicker(1 * time.Second)
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				over := true
+				director.runOnLoop(func() { over = director.tickPicks() })
+				if over {
+					return
+				}
+			case <-director.finishedCh:
+				return
+			}
+		}
+	}()
+}
+
+// tickPicks forces the picks of seats that ran out of time, it is true once
+// the draft is over. It runs on the Listen loop.
+func (director *GameDirector) tickPicks() bool {
+	if director.phase != models.PhaseDrafting {
+		return true
+	}
+	logger := internal.GetLogger()
+	seats := director.tickPickTimers()
+	for _, seat := range seats {
+		logger.Infow("Times Up! Forcing autopick", "game", director.GameId, "seat", seat)
+		if err := director.forcePick(seat); err != nil {
+			logger.Errorw("cannot force pick", "game", director.GameId, "seat", seat, "error", err.Error())
+		}
+	}
+	if len(seats) > 0 {
+		director.pickCardsForBots()
+		director.savePicks()
+	}
+	return false
+}
+
+// getSeatsHoldingPacks lists seats with a pack in front of them in seat order
+func (director *GameDirector) getSeatsHoldingPacks() []int {
+	var seats []int
+	for seat, queue := range director.packQueues {
+		if len(queue) > 0 {
+			seats = append(seats, seat)
+		}
+	}
+	sort.Ints(seats)
+	return seats
+}
@@ -0,0 +1,102 @@
+package director_test
+
+import (
+	"github.com/gorilla/websocket"
+	"github.com/malexanderboyd/pwr9-godr4ft/internal/director"
+	"github.com/malexanderboyd/pwr9-godr4ft/internal/director/models"
+	"github.com/malexanderboyd/pwr9-godr4ft/internal/game"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func pickFirstCard(t *testing.T, ws *websocket.Conn) {
+	var pack models.CardPack
+	if err := director.ReadUntil(t, ws, models.RoundContent).Decode(&pack); err != nil {
+		t.Fatal(err)
+	}
+	_ = ws.WriteJSON(models.NewMessage(models.ChooseCard, &models.ChooseCardJson{UUID: pack.Pack[0].UUID, PackNumber: pack.PackNumber, Round: pack.Round}))
+	director.ReadUntil(t, ws, models.PickConfirmed)
+}
+
+func TestReconnectingPlayerGetsTheirSeatBack(t *testing.T) {
+	options := game.GeneralOptions{TotalPlayers: 2, Type: game.DRAFT, Mode: game.CUBE}
+	options.GameOptions.Draft.Cube = game.DraftCubeOptions{
+		CardsPerPack: 3,
+		TotalPacks:   1,
+		CubeList:     director.SixCardCube,
+	}
+	d := director.NewGameDirector(options, 9000, "reattach_game")
+	if err := d.GetGameResources(); err != nil {
+		t.Fatal(err)
+	}
+	go d.Listen()
+	defer d.Finish()
+	ts := httptest.NewServer(d.Handler())
+	defer ts.Close()
+	wsUrl := "ws" + strings.TrimPrefix(ts.URL, "http") + "/ws"
+
+	host, _, err := websocket.DefaultDialer.Dial(wsUrl, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer host.Close()
+	director.ReadUntil(t, host, models.HostChange)
+	player, res, err := websocket.DefaultDialer.Dial(wsUrl, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	playerCookie := strings.Split(res.Header.Get("Set-Cookie"), ";")[0]
+
+	_ = host.WriteJSON(models.NewMessage(models.GameStart, &models.TimerSettings{}))
+	pickFirstCard(t, host)
+	pickFirstCard(t, player)
+	player.Close()
+
+	header := http.Header{}
+	header.Set("Cookie", playerCookie)
+	player, res, err = websocket.DefaultDialer.Dial(wsUrl, header)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer player.Close()
+	if cookie := strings.Split(res.Header.Get("Set-Cookie"), ";")[0]; cookie != playerCookie {
+		t.Errorf("expected the same seat token back, got %q for %q", cookie, playerCookie)
+	}
+
+	_ = player.SetReadDeadline(time.Now().Add(3 * time.Second))
+	var pack models.CardPack
+	var pool []models.SetCard
+	for pool == nil {
+		var msg models.Message
+		if err := player.ReadJSON(&msg); err != nil {
+			t.Fatalf("expected the player's pack and pool, got %v", err)
+		}
+		switch msg.Type {
+		case models.HostChange:
+			t.Errorf("expected the connected host to keep hosting")
+		case models.RoundContent:
+			_ = msg.Decode(&pack)
+		case models.PoolContent:
+			_ = msg.Decode(&pool)
+		}
+	}
+	if len(pack.Pack) != 2 {
+		t.Errorf("expected the pack the host passed, got %d cards", len(pack.Pack))
+	}
+	if len(pool) != 1 {
+		t.Errorf("expected the player's pick back in their pool, got %d cards", len(pool))
+	}
+
+	header.Set("Cookie", models.DraftCookieName+"=reattach_game_1")
+	guesser, res, err := websocket.DefaultDialer.Dial(wsUrl, header)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer guesser.Close()
+	if cookie := strings.Split(res.Header.Get("Set-Cookie"), ";")[0]; cookie == playerCookie || strings.Contains(cookie, "reattach_game") {
+		t.Errorf("expected a guessed client id to get a fresh seat token, got %q", cookie)
+	}
+}
@@ -0,0 +1,63 @@
+package director
+
+import (
+	"encoding/json"
+	"github.com/malexanderboyd/pwr9-godr4ft/internal/director/models"
+	"net/http"
+	"time"
+)
+
+// recordPick logs a pick, shownAt is when the pack reached the drafter
+func (director *GameDirector) recordPick(clientID string, seat int, pickNumber int, pack []models.SetCard, picked models.SetCard, forced bool, shownAt time.Time) {
+	event := models.PickEvent{
+		Seat:        seat,
+		PlayerId:    clientID,
+		PackNumber:  director.packNumber + 1,
+		PickNumber:  pickNumber,
+		Picked:      models.NewCardRef(picked),
+		Forced:      forced,
+		TimeTakenMs: int64(time.Since(shownAt) / time.Millisecond),
+		PickedAt:    time.Now(),
+	}
+	for _, card := range pack {
+		event.Pack = append(event.Pack, models.NewCardRef(card))
+	}
+
+	director.pickLogMu.Lock()
+	defer director.pickLogMu.Unlock()
+	director.pickLog = append(director.pickLog, event)
+}
+
+func (director *GameDirector) getPickLog() []models.PickEvent {
+	director.pickLogMu.Lock()
+	defer director.pickLogMu.Unlock()
+	return append([]models.PickEvent{}, director.pickLog...)
+}
+
+// serveReplay returns every pick of the draft once it is over, so nobody can
+// peek at what their neighbours are taking mid draft. The seating is read on
+// the Listen loop.
+func (director *GameDirector) serveReplay(w http.ResponseWriter, r *http.Request) {
+	var finished bool
+	seats := make(map[string]int)
+	if !director.runOnLoop(func() {
+		finished = director.hasDraftFinished()
+		for id, seat := range director.Seats {
+			seats[id] = seat
+		}
+	}) {
+		http.Error(w, "the game is over", http.StatusGone)
+		return
+	}
+	if !finished {
+		http.Error(w, "the replay is available once the draft is over", http.StatusConflict)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(&models.DraftReplay{
+		GameId: director.GameId,
+		Seats:  seats,
+		Picks:  director.getPickLog(),
+	})
+}
@@ -0,0 +1,63 @@
+package director_test
+
+import (
+	"encoding/json"
+	"github.com/malexanderboyd/pwr9-godr4ft/internal/director"
+	"github.com/malexanderboyd/pwr9-godr4ft/internal/director/models"
+	"github.com/malexanderboyd/pwr9-godr4ft/internal/game"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestDraftReplay(t *testing.T) {
+	d, _ := director.NewSeatedCubeDraft(t, "replay_game", game.GeneralOptions{}, 0)
+	d.DealPack()
+
+	var botIDs []string
+	for id := range d.Bots {
+		botIDs = append(botIDs, id)
+	}
+	if err := d.ChooseCard(botIDs[0], 1, false); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if err := d.ChooseCard(botIDs[1], 0, true); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	go d.Listen()
+	defer d.Finish()
+
+	rec := httptest.NewRecorder()
+	d.ServeReplay(rec, httptest.NewRequest(http.MethodGet, "/replay", nil))
+	if rec.Code != http.StatusConflict {
+		t.Errorf("expected the replay to be hidden mid draft, got status %d", rec.Code)
+	}
+
+	d.RunOnLoop(func() { d.SetPhase(models.PhaseEnded) })
+	rec = httptest.NewRecorder()
+	d.ServeReplay(rec, httptest.NewRequest(http.MethodGet, "/replay", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected the replay once the draft is over, got status %d", rec.Code)
+	}
+
+	var replay models.DraftReplay
+	if err := json.NewDecoder(rec.Body).Decode(&replay); err != nil {
+		t.Fatalf("cannot decode replay %v", err)
+	}
+
+	if len(replay.Picks) != 2 {
+		t.Fatalf("expected 2 picks, got %d", len(replay.Picks))
+	}
+
+	first := replay.Picks[0]
+	if first.PlayerId != botIDs[0] || first.PackNumber != 1 || first.PickNumber != 1 || first.Forced {
+		t.Errorf("unexpected first pick %+v", first)
+	}
+	if len(first.Pack) != 3 || first.Picked != first.Pack[1] {
+		t.Errorf("expected the first pick to be the second of 3 cards shown, got %+v from %+v", first.Picked, first.Pack)
+	}
+
+	if !replay.Picks[1].Forced {
+		t.Errorf("expected the second pick to be forced")
+	}
+}
@@ -0,0 +1,15 @@
+package director
+
+import "github.com/malexanderboyd/pwr9-godr4ft/internal/director/models"
+
+// getSealedPools opens every pack round at once, each seat's pool is all of
+// the packs dealt to that seat.
+func getSealedPools(rounds map[int]models.DraftRound) map[int][]models.SetCard {
+	pools := make(map[int][]models.SetCard)
+	for packNumber := 0; packNumber < len(rounds); packNumber++ {
+		for seat, pack := range rounds[packNumber].PlayerPacks {
+			pools[seat] = append(pools[seat], pack...)
+		}
+	}
+	return pools
+}
@@ -0,0 +1,464 @@
+package director
+
+import (
+	"errors"
+	"fmt"
+	"github.com/malexanderboyd/pwr9-godr4ft/internal"
+	"github.com/malexanderboyd/pwr9-godr4ft/internal/director/models"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// games are served under /game/{id}/, ie: /game/a1b2c3d4/ws
+const GamePathPrefix = "/game/"
+
+// FinishedGameTTL is how long the server remembers a game has finished
+const FinishedGameTTL = 24 * time.Hour
+
+// ErrGameFinished is returned for a game that has already been played out
+var ErrGameFinished = errors.New("game has finished")
+
+var gameIdRegex = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+type ServerConfig struct {
+	// a game to create at startup, it is also served on the bare /ws, /export
+	// and /replay routes for clients that predate /game/{id}/
+	GameId string
+	Port   int
+	// where GameId is snapshotted after every round, defaults to SnapshotDir
+	SnapshotPath string
+	// where games are snapshotted and correspondence drafts are resumed from
+	// at startup, defaults to the temp dir which may not survive a restart
+	SnapshotDir string
+	// a snapshot to resume instead of starting GameId from scratch
+	ResumePath string
+	// how long a game stays up after end_game so players can export pools
+	EndGracePeriod time.Duration
+	// how long a finished game's id is answered with 410 Gone instead of
+	// being fetched again, defaults to FinishedGameTTL
+	FinishedGameTTL time.Duration
+	// how long a lobby nobody is connected to stays up, defaults to
+	// LobbyIdleTimeout
+	LobbyIdleTimeout time.Duration
+	// how long a started game everyone has left stays up, defaults to
+	// AbandonedGameTimeout. Correspondence drafts are never removed for it.
+	AbandonedGameTimeout time.Duration
+	// where game options and boosters come from, defaults to the API at ApiUri
+	CardSource CardSource
+	// opens boosters of sets the default API has none for, see HTTPCardSource
+	BoosterFallback CardSource
+	// tells correspondence drafters a pack is waiting, defaults to logging it
+	Notifier Notifier
+	// signs join tokens, see NewJoinToken, private games need it to be joined
+	JoinSecret []byte
+	// origins browsers may open a websocket from, ie: https://draft.example.com,
+	// any origin is allowed when empty
+	AllowedOrigins []string
+	// carries chat mutes and rate limits over to anyone joining from the same
+	// address, only for servers whose players don't share a proxy or NAT
+	ChatLimitsByAddress bool
+}
+
+// GameServer hosts many games at once, routing requests to a game's director
+// by the id in the path. Directors are created the first time a player joins
+// and removed once their game finishes.
+type GameServer struct {
+	config    ServerConfig
+	directors map[string]*GameDirector
+	handlers  map[string]http.Handler
+	// games being fetched, keyed by game id
+	loading map[string]*gameLoad
+	// when each finished game's id may be used again, keyed by game id
+	finished map[string]time.Time
+	// the games the server was started with are still loading
+	starting bool
+	mu       sync.Mutex
+}
+
+// gameLoad is a game being fetched from its snapshot or the API, everyone
+// asking for the game meanwhile waits on done instead of fetching it again
+type gameLoad struct {
+	done chan bool
+	err  error
+}
+
+func NewGameServer(config ServerConfig) *GameServer {
+	return &GameServer{
+		config:    config,
+		directors: make(map[string]*GameDirector),
+		handlers:  make(map[string]http.Handler),
+		loading:   make(map[string]*gameLoad),
+		finished:  make(map[string]time.Time),
+	}
+}
+
+// Handler serves a game's websocket, pool export and replay routes
+func (director *GameDirector) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/ws", director.newClient)
+	mux.HandleFunc("/export", director.requireJoinToken(director.exportPool))
+	mux.HandleFunc("/replay", director.requireJoinToken(director.serveReplay))
+	mux.HandleFunc("/status", director.requireJoinToken(director.serveStatus))
+	return mux
+}
+
+// AddGame starts director's Listen loop and routes its game id to it
+func (server *GameServer) AddGame(director *GameDirector) {
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	server.addGame(director)
+}
+
+func (server *GameServer) addGame(director *GameDirector) {
+	if director.GameId != server.config.GameId {
+		director.basePath = GamePathPrefix + director.GameId + "/"
+	}
+	director.endGracePeriod = server.config.EndGracePeriod
+	if server.config.LobbyIdleTimeout > 0 {
+		director.idleTimeout = server.config.LobbyIdleTimeout
+	}
+	if server.config.AbandonedGameTimeout > 0 {
+		director.abandonedTimeout = server.config.AbandonedGameTimeout
+	}
+	if director.snapshotPath == "" {
+		director.snapshotPath = getSnapshotPath(server.config.SnapshotDir, director.GameId)
+	}
+	director.onFinished = server.removeGame
+	if director.cardSource == nil {
+		director.cardSource = server.config.CardSource
+	}
+	if director.notifier == nil {
+		director.notifier = server.config.Notifier
+	}
+	director.joinSecret = server.config.JoinSecret
+	director.allowedOrigins = server.config.AllowedOrigins
+	director.chatLimitsByAddress = server.config.ChatLimitsByAddress
+	server.directors[director.GameId] = director
+	server.handlers[director.GameId] = director.Handler()
+	activeGames.Inc()
+	go director.Listen()
+	internal.GetLogger().Infow("Added game", "game", director.GameId, "games", len(server.directors))
+}
+
+// removeGame drops a finished director, a game that ended is remembered so
+// reconnecting tabs don't bring it back. An idle lobby can be joined again.
+func (server *GameServer) removeGame(director *GameDirector, ended bool) {
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	if server.directors[director.GameId] == director {
+		delete(server.directors, director.GameId)
+		delete(server.handlers, director.GameId)
+		activeGames.Dec()
+	}
+	if ended {
+		ttl := server.config.FinishedGameTTL
+		if ttl <= 0 {
+			ttl = FinishedGameTTL
+		}
+		now := time.Now()
+		for gameId, expiry := range server.finished {
+			if now.After(expiry) {
+				delete(server.finished, gameId)
+			}
+		}
+		server.finished[director.GameId] = now.Add(ttl)
+	}
+	internal.GetLogger().Infow("Removed game", "game", director.GameId, "games", len(server.directors))
+}
+
+// isFinished is true for a game that ended within FinishedGameTTL, callers
+// hold server.mu
+func (server *GameServer) isFinished(gameId string) bool {
+	expiry, ok := server.finished[gameId]
+	if ok && time.Now().After(expiry) {
+		delete(server.finished, gameId)
+		return false
+	}
+	return ok
+}
+
+func (server *GameServer) GetGame(gameId string) (*GameDirector, bool) {
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	director, ok := server.directors[gameId]
+	return director, ok
+}
+
+func (server *GameServer) GameIds() []string {
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	var ids []string
+	for id := range server.directors {
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+// getOrCreateGame returns the director for gameId, a game that is not running
+// yet resumes from its snapshot if it has one or is fetched from the API. The
+// fetch happens outside server.mu and only once however many players join.
+// A game that has finished is not created again, ErrGameFinished is returned.
+func (server *GameServer) getOrCreateGame(gameId string) (*GameDirector, http.Handler, error) {
+	server.mu.Lock()
+	if director, ok := server.directors[gameId]; ok {
+		handler := server.handlers[gameId]
+		server.mu.Unlock()
+		return director, handler, nil
+	}
+	if server.isFinished(gameId) {
+		server.mu.Unlock()
+		return nil, nil, ErrGameFinished
+	}
+	if load, ok := server.loading[gameId]; ok {
+		server.mu.Unlock()
+		<-load.done
+		if load.err != nil {
+			return nil, nil, load.err
+		}
+		return server.getOrCreateGame(gameId)
+	}
+	load := &gameLoad{done: make(chan bool)}
+	server.loading[gameId] = load
+	server.mu.Unlock()
+
+	director, err := server.newDirector(gameId)
+	var handler http.Handler
+	server.mu.Lock()
+	delete(server.loading, gameId)
+	if err == nil {
+		server.addGame(director)
+		handler = server.handlers[gameId]
+	}
+	server.mu.Unlock()
+	load.err = err
+	close(load.done)
+	if err != nil {
+		return nil, nil, err
+	}
+	return director, handler, nil
+}
+
+func (server *GameServer) newDirector(gameId string) (*GameDirector, error) {
+	if !gameIdRegex.MatchString(gameId) {
+		return nil, errors.New(fmt.Sprintf("invalid game id: %q", gameId))
+	}
+
+	snapshotPath := getSnapshotPath(server.config.SnapshotDir, gameId)
+	if gameId == server.config.GameId && server.config.SnapshotPath != "" {
+		snapshotPath = server.config.SnapshotPath
+	}
+	if _, err := os.Stat(snapshotPath); err == nil {
+		// a lobby snapshot would come back as a started game with nobody seated
+		if snapshot, err := LoadSnapshot(snapshotPath); err == nil && snapshot.GameId == gameId && snapshot.Phase != models.PhaseLobby {
+			director := NewGameDirectorFromSnapshot(snapshot, server.config.Port)
+			director.snapshotPath = snapshotPath
+			return director, nil
+		}
+	}
+
+	source := server.config.CardSource
+	if source == nil {
+		httpSource := NewHTTPCardSource(ApiUri)
+		httpSource.Fallback = server.config.BoosterFallback
+		source = httpSource
+	}
+	gameOptions, err := source.GetGameOptions(gameId)
+	if err != nil {
+		return nil, err
+	}
+	director := NewGameDirector(gameOptions, server.config.Port, gameId)
+	director.cardSource = source
+	if err := director.getGameResources(); err != nil {
+		return nil, err
+	}
+	director.snapshotPath = snapshotPath
+	return director, nil
+}
+
+// resumeCorrespondenceGames brings back every correspondence draft with a
+// snapshot in SnapshotDir, their deadlines have to run even if no player
+// comes back to wake the game up
+func (server *GameServer) resumeCorrespondenceGames() {
+	logger := internal.GetLogger()
+	paths, err := filepath.Glob(getSnapshotPath(server.config.SnapshotDir, "*"))
+	if err != nil {
+		logger.Errorw("cannot list snapshots", "error", err.Error())
+		return
+	}
+	for _, path := range paths {
+		snapshot, err := LoadSnapshot(path)
+		if err != nil || snapshot.Options.PickDeadlineHours <= 0 || snapshot.Phase == models.PhaseLobby || path != getSnapshotPath(server.config.SnapshotDir, snapshot.GameId) {
+			continue
+		}
+		if _, _, err := server.getOrCreateGame(snapshot.GameId); err != nil {
+			logger.Errorw("cannot resume correspondence game", "game", snapshot.GameId, "error", err.Error())
+		}
+	}
+}
+
+// ServeHTTP routes /game/{id}/... to the game's director, joining the
+// websocket of an unknown game creates it.
+func (server *GameServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	rest := strings.TrimPrefix(r.URL.Path, GamePathPrefix)
+	parts := strings.SplitN(rest, "/", 2)
+	if len(parts) != 2 || parts[0] == "" {
+		http.NotFound(w, r)
+		return
+	}
+	server.serveGame(w, r, parts[0], "/"+parts[1])
+}
+
+func (server *GameServer) serveGame(w http.ResponseWriter, r *http.Request, gameId, path string) {
+	var handler http.Handler
+	if path == "/ws" {
+		var err error
+		if _, handler, err = server.getOrCreateGame(gameId); err == ErrGameFinished {
+			http.Error(w, err.Error(), http.StatusGone)
+			return
+		} else if err != nil {
+			internal.GetLogger().Errorw("cannot create game", "game", gameId, "error", err.Error())
+			http.Error(w, "cannot load game", http.StatusNotFound)
+			return
+		}
+	} else {
+		server.mu.Lock()
+		handler = server.handlers[gameId]
+		finished := handler == nil && server.isFinished(gameId)
+		server.mu.Unlock()
+		if finished {
+			http.Error(w, ErrGameFinished.Error(), http.StatusGone)
+			return
+		}
+		if handler == nil {
+			http.NotFound(w, r)
+			return
+		}
+	}
+
+	r2 := new(http.Request)
+	*r2 = *r
+	r2.URL = new(url.URL)
+	*r2.URL = *r.URL
+	r2.URL.Path = path
+	handler.ServeHTTP(w, r2)
+}
+
+// Handler serves every game under /game/{id}/ along with the server's own
+// metrics and health checks
+func (server *GameServer) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle(GamePathPrefix, server)
+	mux.Handle("/metrics", promhttp.Handler())
+	mux.HandleFunc("/healthz", server.serveHealthz)
+	mux.HandleFunc("/readyz", server.serveReadyz)
+	if server.config.GameId != "" {
+		for _, path := range []string{"/ws", "/export", "/replay", "/status"} {
+			path := path
+			mux.HandleFunc(path, func(w http.ResponseWriter, r *http.Request) {
+				server.serveGame(w, r, server.config.GameId, path)
+			})
+		}
+	}
+	mux.Handle("/", http.FileServer(http.Dir("webroot")))
+	return mux
+}
+
+// serveHealthz answers as long as the server is handling requests
+func (server *GameServer) serveHealthz(w http.ResponseWriter, r *http.Request) {
+	_, _ = fmt.Fprintln(w, "ok")
+}
+
+// serveReadyz answers once the server can take players, not while the games
+// it was started with are loading. A server started for one game is only
+// ready while that game's boosters are loaded and it hasn't ended, once it
+// ends the server is just waiting out the grace period.
+func (server *GameServer) serveReadyz(w http.ResponseWriter, r *http.Request) {
+	if err := server.checkReady(); err != nil {
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+	_, _ = fmt.Fprintln(w, "ok")
+}
+
+func (server *GameServer) checkReady() error {
+	server.mu.Lock()
+	starting := server.starting
+	server.mu.Unlock()
+	if starting {
+		return errors.New("still loading games")
+	}
+	gameId := server.config.GameId
+	if gameId == "" {
+		return nil
+	}
+	director, ok := server.GetGame(gameId)
+	if !ok {
+		return errors.New(fmt.Sprintf("game %s is not loaded", gameId))
+	}
+	if director.GetStatus().Phase == models.PhaseEnded {
+		return errors.New(fmt.Sprintf("game %s has ended", gameId))
+	}
+	return nil
+}
+
+// startLoadingGames loads the game the server was started for and resumes
+// correspondence drafts in the background, /readyz fails until they are up
+func (server *GameServer) startLoadingGames() {
+	server.mu.Lock()
+	server.starting = true
+	server.mu.Unlock()
+	go func() {
+		if server.config.GameId != "" {
+			// the server keeps hosting other games, /readyz reports this one missing
+			if _, _, err := server.getOrCreateGame(server.config.GameId); err != nil {
+				internal.GetLogger().Errorw("cannot load game", "game", server.config.GameId, "error", err.Error())
+			}
+		}
+		server.resumeCorrespondenceGames()
+		server.mu.Lock()
+		server.starting = false
+		server.mu.Unlock()
+	}()
+}
+
+// StartDraftServer serves games until the server fails, it returns an error
+// if the snapshot dir or the game to resume can't be set up
+func StartDraftServer(config ServerConfig) error {
+	ApiUri = getAPIUrlFromEnv("NODE_ENV")
+	if config.CardSource == nil {
+		source := NewHTTPCardSource(ApiUri)
+		source.Fallback = config.BoosterFallback
+		config.CardSource = source
+	}
+	if config.Notifier == nil {
+		config.Notifier = &LogNotifier{}
+	}
+	if config.SnapshotDir != "" {
+		if err := os.MkdirAll(config.SnapshotDir, 0700); err != nil {
+			return errors.New(fmt.Sprintf("cannot create snapshot dir %s: %s", config.SnapshotDir, err.Error()))
+		}
+	}
+
+	server := NewGameServer(config)
+	if config.ResumePath != "" {
+		snapshot, err := LoadSnapshot(config.ResumePath)
+		if err != nil {
+			return errors.New(fmt.Sprintf("cannot resume game from %s: %s", config.ResumePath, err.Error()))
+		}
+		server.config.GameId = snapshot.GameId
+		director := NewGameDirectorFromSnapshot(snapshot, config.Port)
+		director.snapshotPath = config.SnapshotPath
+		server.AddGame(director)
+	}
+	// loading can take a while, the orchestrator sees /readyz fail until it's done
+	server.startLoadingGames()
+
+	return http.ListenAndServe(fmt.Sprintf(":%d", config.Port), server.Handler())
+}
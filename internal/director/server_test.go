@@ -0,0 +1,346 @@
+package director_test
+
+import (
+	"encoding/json"
+	"github.com/gorilla/websocket"
+	"github.com/malexanderboyd/pwr9-godr4ft/internal/director"
+	"github.com/malexanderboyd/pwr9-godr4ft/internal/director/models"
+	"github.com/malexanderboyd/pwr9-godr4ft/internal/game"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestGameServerHostsGamesById(t *testing.T) {
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.URL.Path, "/game/") {
+			if r.URL.Path == "/game/missing" {
+				http.NotFound(w, r)
+				return
+			}
+			_ = json.NewEncoder(w).Encode(game.GeneralOptions{
+				TotalPlayers: 2,
+				Type:         game.DRAFT,
+				Mode:         game.REGULAR,
+				GameOptions: game.ModeMap{
+					Draft: game.DraftOptions{
+						Regular: game.DraftRegularOptions{TotalPacks: 1, SelectedPacks: map[string]string{"0": "M20"}},
+					},
+				},
+			})
+			return
+		}
+		n, _ := strconv.Atoi(r.URL.Query().Get("n"))
+		var boosters models.SetPacks
+		for i := 0; i < n; i++ {
+			boosters.Packs = append(boosters.Packs, []models.SetCard{{Name: "Shock"}})
+		}
+		_ = json.NewEncoder(w).Encode(boosters)
+	}))
+	defer api.Close()
+	director.ApiUri = api.URL
+
+	server := director.NewGameServer(director.ServerConfig{})
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+	wsUrl := "ws" + strings.TrimPrefix(ts.URL, "http")
+
+	for _, gameId := range []string{"server_game_1", "server_game_2"} {
+		ws, res, err := websocket.DefaultDialer.Dial(wsUrl+"/game/"+gameId+"/ws", nil)
+		if err != nil {
+			t.Fatalf("cannot join %s: %v", gameId, err)
+		}
+		defer ws.Close()
+		if cookie := res.Header.Get("Set-Cookie"); !strings.Contains(cookie, "Path=/game/"+gameId+"/") {
+			t.Errorf("expected the draft cookie to be scoped to the game, got %q", cookie)
+		}
+	}
+	if ids := server.GameIds(); len(ids) != 2 {
+		t.Fatalf("expected 2 games, got %v", ids)
+	}
+
+	if _, _, err := websocket.DefaultDialer.Dial(wsUrl+"/game/missing/ws", nil); err == nil {
+		t.Errorf("expected an error joining a game the API does not know")
+	}
+	if res, err := http.Get(ts.URL + "/game/unknown/replay"); err != nil || res.StatusCode != http.StatusNotFound {
+		t.Errorf("expected a 404 for a game that is not running, got %v %v", res, err)
+	}
+
+	d, _ := server.GetGame("server_game_1")
+	d.Finish()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if _, ok := server.GetGame("server_game_1"); !ok {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expected the finished game to be removed")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if _, ok := server.GetGame("server_game_2"); !ok {
+		t.Errorf("expected the other game to keep running")
+	}
+}
+
+func TestGameServerFetchesAGameOnce(t *testing.T) {
+	var mu sync.Mutex
+	fetches := make(map[string]int)
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.URL.Path, "/game/") {
+			mu.Lock()
+			fetches[r.URL.Path]++
+			mu.Unlock()
+			time.Sleep(50 * time.Millisecond)
+			_ = json.NewEncoder(w).Encode(game.GeneralOptions{
+				TotalPlayers: 2,
+				Type:         game.DRAFT,
+				Mode:         game.REGULAR,
+				GameOptions: game.ModeMap{
+					Draft: game.DraftOptions{
+						Regular: game.DraftRegularOptions{TotalPacks: 1, SelectedPacks: map[string]string{"0": "M20"}},
+					},
+				},
+			})
+			return
+		}
+		n, _ := strconv.Atoi(r.URL.Query().Get("n"))
+		var boosters models.SetPacks
+		for i := 0; i < n; i++ {
+			boosters.Packs = append(boosters.Packs, []models.SetCard{{Name: "Shock"}})
+		}
+		_ = json.NewEncoder(w).Encode(boosters)
+	}))
+	defer api.Close()
+	director.ApiUri = api.URL
+
+	server := director.NewGameServer(director.ServerConfig{})
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+	wsUrl := "ws" + strings.TrimPrefix(ts.URL, "http") + "/game/fetch_once_game/ws"
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ws, _, err := websocket.DefaultDialer.Dial(wsUrl, nil)
+			if err != nil {
+				t.Errorf("cannot join: %v", err)
+				return
+			}
+			ws.Close()
+		}()
+	}
+	wg.Wait()
+	if res, err := http.Get(ts.URL + "/game/status_only_game/status"); err != nil || res.StatusCode != http.StatusNotFound {
+		t.Errorf("expected a 404 for the status of a game nobody joined, got %v %v", res, err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if fetches["/game/fetch_once_game"] != 1 {
+		t.Errorf("expected the game to be fetched once, got %d fetches", fetches["/game/fetch_once_game"])
+	}
+	if fetches["/game/status_only_game"] != 0 {
+		t.Errorf("expected only joining a game to fetch it")
+	}
+	d, _ := server.GetGame("fetch_once_game")
+	d.Finish()
+}
+
+func TestGameServerFinishedGamesStayGone(t *testing.T) {
+	var mu sync.Mutex
+	fetches := 0
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.URL.Path, "/game/") {
+			mu.Lock()
+			fetches++
+			mu.Unlock()
+			_ = json.NewEncoder(w).Encode(game.GeneralOptions{
+				TotalPlayers: 2,
+				Type:         game.DRAFT,
+				Mode:         game.REGULAR,
+				GameOptions: game.ModeMap{
+					Draft: game.DraftOptions{
+						Regular: game.DraftRegularOptions{TotalPacks: 1, SelectedPacks: map[string]string{"0": "M20"}},
+					},
+				},
+			})
+			return
+		}
+		n, _ := strconv.Atoi(r.URL.Query().Get("n"))
+		var boosters models.SetPacks
+		for i := 0; i < n; i++ {
+			boosters.Packs = append(boosters.Packs, []models.SetCard{{Name: "Shock"}})
+		}
+		_ = json.NewEncoder(w).Encode(boosters)
+	}))
+	defer api.Close()
+	director.ApiUri = api.URL
+
+	server := director.NewGameServer(director.ServerConfig{LobbyIdleTimeout: 50 * time.Millisecond})
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+	wsUrl := "ws" + strings.TrimPrefix(ts.URL, "http")
+	waitForRemoval := func(gameId string) {
+		deadline := time.Now().Add(5 * time.Second)
+		for {
+			if _, ok := server.GetGame(gameId); !ok {
+				return
+			}
+			if time.Now().After(deadline) {
+				t.Fatalf("expected %s to be removed", gameId)
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+
+	ws, _, err := websocket.DefaultDialer.Dial(wsUrl+"/game/ended_game/ws", nil)
+	if err != nil {
+		t.Fatalf("cannot join: %v", err)
+	}
+	defer ws.Close()
+	d, _ := server.GetGame("ended_game")
+	d.Finish()
+	waitForRemoval("ended_game")
+
+	if _, res, err := websocket.DefaultDialer.Dial(wsUrl+"/game/ended_game/ws", nil); err == nil || res == nil || res.StatusCode != http.StatusGone {
+		t.Errorf("expected a 410 rejoining a finished game, got %v %v", res, err)
+	}
+	if res, err := http.Get(ts.URL + "/game/ended_game/replay"); err != nil || res.StatusCode != http.StatusGone {
+		t.Errorf("expected a 410 for the replay of a finished game, got %v %v", res, err)
+	}
+	mu.Lock()
+	if fetches != 1 {
+		t.Errorf("expected a finished game not to be fetched again, got %d fetches", fetches)
+	}
+	mu.Unlock()
+
+	idle, _, err := websocket.DefaultDialer.Dial(wsUrl+"/game/idle_game/ws", nil)
+	if err != nil {
+		t.Fatalf("cannot join: %v", err)
+	}
+	idle.Close()
+	waitForRemoval("idle_game")
+
+	// an idle lobby never started, it can be opened again
+	idle, _, err = websocket.DefaultDialer.Dial(wsUrl+"/game/idle_game/ws", nil)
+	if err != nil {
+		t.Fatalf("cannot rejoin an idle lobby: %v", err)
+	}
+	defer idle.Close()
+	if _, ok := server.GetGame("idle_game"); !ok {
+		t.Errorf("expected the idle lobby to be opened again")
+	}
+}
+
+func TestGameServerRemovesAbandonedGames(t *testing.T) {
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.URL.Path, "/game/") {
+			options := game.GeneralOptions{TotalPlayers: 2, Type: game.DRAFT, Mode: game.CUBE}
+			options.GameOptions.Draft.Cube = game.DraftCubeOptions{CardsPerPack: 3, TotalPacks: 1, CubeList: director.SixCardCube}
+			_ = json.NewEncoder(w).Encode(options)
+			return
+		}
+		http.NotFound(w, r)
+	}))
+	defer api.Close()
+	director.ApiUri = api.URL
+	dir, err := ioutil.TempDir("", "godr4ft")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	server := director.NewGameServer(director.ServerConfig{SnapshotDir: dir, AbandonedGameTimeout: 50 * time.Millisecond})
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+	wsUrl := "ws" + strings.TrimPrefix(ts.URL, "http") + "/game/abandoned_game/ws"
+
+	host, _, err := websocket.DefaultDialer.Dial(wsUrl, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	director.ReadUntil(t, host, models.HostChange)
+	player, _, err := websocket.DefaultDialer.Dial(wsUrl, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = host.WriteJSON(models.NewMessage(models.GameStart, &models.TimerSettings{}))
+	director.ReadUntil(t, player, models.RoundContent)
+	host.Close()
+	player.Close()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if _, ok := server.GetGame("abandoned_game"); !ok {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expected the abandoned draft to be removed")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// it was parked rather than finished, coming back resumes the draft
+	back, _, err := websocket.DefaultDialer.Dial(wsUrl, nil)
+	if err != nil {
+		t.Fatalf("cannot come back to an abandoned draft: %v", err)
+	}
+	defer back.Close()
+	d, ok := server.GetGame("abandoned_game")
+	if !ok || d.GetStatus().Phase != models.PhaseDrafting {
+		t.Errorf("expected the draft to resume from its snapshot")
+	}
+	d.Finish()
+}
+
+func TestGameServerDoesNotRestoreLobbySnapshots(t *testing.T) {
+	var mu sync.Mutex
+	fetches := 0
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.URL.Path, "/game/") {
+			mu.Lock()
+			fetches++
+			mu.Unlock()
+			options := game.GeneralOptions{TotalPlayers: 2, Type: game.DRAFT, Mode: game.CUBE}
+			options.GameOptions.Draft.Cube = game.DraftCubeOptions{CardsPerPack: 3, TotalPacks: 1, CubeList: director.SixCardCube}
+			_ = json.NewEncoder(w).Encode(options)
+			return
+		}
+		http.NotFound(w, r)
+	}))
+	defer api.Close()
+	director.ApiUri = api.URL
+	dir, err := ioutil.TempDir("", "godr4ft")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	lobby := director.NewGameDirector(game.GeneralOptions{}, 9000, "lobby_snapshot_game")
+	lobby.SaveSnapshot(director.GetSnapshotPath(dir, lobby.GameId))
+
+	server := director.NewGameServer(director.ServerConfig{SnapshotDir: dir})
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+	ws, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(ts.URL, "http")+"/game/lobby_snapshot_game/ws", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ws.Close()
+	mu.Lock()
+	if fetches != 1 {
+		t.Errorf("expected a fresh lobby from the API rather than the lobby snapshot, got %d fetches", fetches)
+	}
+	mu.Unlock()
+	d, _ := server.GetGame("lobby_snapshot_game")
+	d.Finish()
+}
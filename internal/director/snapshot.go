@@ -0,0 +1,239 @@
+package director
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/malexanderboyd/pwr9-godr4ft/internal"
+	"github.com/malexanderboyd/pwr9-godr4ft/internal/director/models"
+	"github.com/malexanderboyd/pwr9-godr4ft/internal/game"
+	"github.com/malexanderboyd/pwr9-godr4ft/internal/tournament"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Snapshot is everything a director needs to pick a draft back up after the
+// server restarts.
+type Snapshot struct {
+	GameId                    string                       `json:"gameId"`
+	Options                   game.GeneralOptions          `json:"options"`
+	Phase                     models.GamePhase             `json:"phase"`
+	PackNumber                int                          `json:"packNumber"`
+	TotalPacks                int                          `json:"totalPacks"`
+	RoundTimerType            string                       `json:"roundTimerType"`
+	RoundTimerServerForcePick bool                         `json:"roundTimerServerForcePick"`
+	RoundPacks                map[int]models.DraftRound    `json:"roundPacks"`
+	PackQueues                map[int][]*models.QueuedPack `json:"packQueues"`
+	SeatPicks                 map[int]int                  `json:"seatPicks"`
+	PickTimers                map[int]*SnapshotPickTimer   `json:"pickTimers"`
+	Seats                     map[string]int               `json:"seats"`
+	Bots                      []string                     `json:"bots"`
+	Pools                     map[string][]models.SetCard  `json:"pools"`
+	Host                      string                       `json:"host"`
+	Messages                  []*models.Message            `json:"messages"`
+	LastChatId                int                          `json:"lastChatId"`
+	PickLog                   []models.PickEvent           `json:"pickLog"`
+	Decks                     map[string]models.Deck       `json:"decks"`
+	Names                     map[string]string            `json:"names"`
+	SeatTokens                map[string]string            `json:"seatTokens"`
+	Tournament                *tournament.Tournament       `json:"tournament"`
+}
+
+// SnapshotPickTimer is a seat's pick timer, correspondence deadlines run for
+// hours and have to outlive the server
+type SnapshotPickTimer struct {
+	ShownAt   time.Time     `json:"shownAt"`
+	Elapsed   time.Duration `json:"elapsed"`
+	Extension time.Duration `json:"extension"`
+}
+
+// getSnapshotPath is where gameId is snapshotted in dir, the temp dir when
+// dir is empty
+func getSnapshotPath(dir, gameId string) string {
+	if dir == "" {
+		dir = os.TempDir()
+	}
+	return filepath.Join(dir, fmt.Sprintf("godr4ft-%s.json", gameId))
+}
+
+// getSnapshot shares the director's maps rather than copying them, callers
+// hold pickMu until the snapshot is encoded
+func (director *GameDirector) getSnapshot() *Snapshot {
+	snapshot := &Snapshot{
+		GameId:                    director.GameId,
+		Options:                   director.options,
+		Phase:                     director.phase,
+		PackNumber:                director.packNumber,
+		TotalPacks:                director.totalPacks,
+		RoundTimerType:            director.roundTimerType,
+		RoundTimerServerForcePick: director.roundTimerServerForcePick,
+		RoundPacks:                director.roundPacks,
+		PackQueues:                director.packQueues,
+		SeatPicks:                 director.seatPicks,
+		PickTimers:                make(map[int]*SnapshotPickTimer),
+		Seats:                     director.Seats,
+		Pools:                     make(map[string][]models.SetCard),
+		Decks:                     make(map[string]models.Deck),
+		Names:                     make(map[string]string),
+		SeatTokens:                make(map[string]string),
+		Host:                      director.host,
+		Messages:                  director.messages,
+		PickLog:                   director.getPickLog(),
+		Tournament:                director.tournament,
+	}
+	director.adminMu.Lock()
+	snapshot.LastChatId = director.lastChatId
+	director.adminMu.Unlock()
+	for seat, timer := range director.pickTimers {
+		snapshot.PickTimers[seat] = &SnapshotPickTimer{
+			ShownAt:   timer.shownAt,
+			Elapsed:   timer.elapsed,
+			Extension: timer.extension,
+		}
+	}
+	for id := range director.Seats {
+		if client, ok := director.Clients[id]; ok {
+			snapshot.Pools[id] = client.getPool()
+			snapshot.Decks[id] = client.deck
+			snapshot.Names[id] = client.getName()
+			snapshot.SeatTokens[id] = client.seatToken
+		} else if bot, ok := director.Bots[id]; ok {
+			snapshot.Bots = append(snapshot.Bots, id)
+			snapshot.Pools[id] = bot.pool
+		}
+	}
+	return snapshot
+}
+
+// saveSnapshot writes the director's state to its snapshot path, the file is
+// replaced in one rename so a crash mid write never leaves half a snapshot.
+func (director *GameDirector) saveSnapshot() {
+	if director.snapshotPath == "" {
+		return
+	}
+
+	director.snapshotMu.Lock()
+	defer director.snapshotMu.Unlock()
+	logger := internal.GetLogger()
+	director.pickMu.Lock()
+	snapshot, err := json.Marshal(director.getSnapshot())
+	director.pickMu.Unlock()
+	if err != nil {
+		logger.Errorw("cannot encode snapshot", "error", err.Error())
+		return
+	}
+
+	tmpPath := director.snapshotPath + ".tmp"
+	if err := ioutil.WriteFile(tmpPath, snapshot, 0600); err != nil {
+		logger.Errorw("cannot write snapshot", "path", tmpPath, "error", err.Error())
+		return
+	}
+	if err := os.Rename(tmpPath, director.snapshotPath); err != nil {
+		logger.Errorw("cannot write snapshot", "path", director.snapshotPath, "error", err.Error())
+		return
+	}
+	logger.Debugw("Saved snapshot", "path", director.snapshotPath, "pack_number", director.packNumber)
+}
+
+func (director *GameDirector) removeSnapshot() {
+	if director.snapshotPath == "" {
+		return
+	}
+	if err := os.Remove(director.snapshotPath); err != nil && !os.IsNotExist(err) {
+		internal.GetLogger().Errorw("cannot remove snapshot", "path", director.snapshotPath, "error", err.Error())
+	}
+}
+
+func LoadSnapshot(path string) (*Snapshot, error) {
+	contents, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var snapshot Snapshot
+	if err := json.Unmarshal(contents, &snapshot); err != nil {
+		return nil, err
+	}
+	return &snapshot, nil
+}
+
+// NewGameDirectorFromSnapshot rebuilds a director mid draft, every seated
+// player is restored as a disconnected client waiting for its cookie.
+func NewGameDirectorFromSnapshot(snapshot *Snapshot, port int) *GameDirector {
+	director := NewGameDirector(snapshot.Options, port, snapshot.GameId)
+	director.gameStarted = true
+	director.resuming = true
+	director.phase = snapshot.Phase
+	director.packNumber = snapshot.PackNumber
+	director.totalPacks = snapshot.TotalPacks
+	director.roundTimerType = snapshot.RoundTimerType
+	director.roundTimerServerForcePick = snapshot.RoundTimerServerForcePick
+	director.roundPacks = snapshot.RoundPacks
+	if snapshot.PackQueues != nil {
+		director.packQueues = snapshot.PackQueues
+	}
+	if snapshot.SeatPicks != nil {
+		director.seatPicks = snapshot.SeatPicks
+	}
+	for seat, timer := range snapshot.PickTimers {
+		director.pickTimers[seat] = &pickTimer{
+			shownAt:   timer.ShownAt,
+			elapsed:   timer.Elapsed,
+			extension: timer.Extension,
+		}
+	}
+	director.Seats = snapshot.Seats
+	director.host = snapshot.Host
+	director.messages = snapshot.Messages
+	director.lastChatId = snapshot.LastChatId
+	director.pickLog = snapshot.PickLog
+	director.tournament = snapshot.Tournament
+
+	bots := make(map[string]bool)
+	for _, id := range snapshot.Bots {
+		bots[id] = true
+	}
+
+	for id, seat := range snapshot.Seats {
+		if bots[id] {
+			strategy, err := NewBotStrategy(director.options.BotStrategy, director.rng)
+			if err != nil {
+				strategy, _ = NewBotStrategy(DefaultBotStrategy, director.rng)
+			}
+			bot := NewBot(director, seat, strategy)
+			bot.pool = snapshot.Pools[id]
+			director.Bots[id] = bot
+		} else {
+			client := newDisconnectedClient(director, id)
+			client.pool = snapshot.Pools[id]
+			client.deck = snapshot.Decks[id]
+			if name, ok := snapshot.Names[id]; ok {
+				client.name = name
+			}
+			if seatToken, ok := snapshot.SeatTokens[id]; ok {
+				client.seatToken = seatToken
+				director.seatTokens[seatToken] = id
+			}
+			director.Clients[id] = client
+		}
+	}
+	return director
+}
+
+// newDisconnectedClient restores a client by id, new client ids are bumped past
+// it so they never collide.
+func newDisconnectedClient(director *GameDirector, clientID string) *Client {
+	if i := strings.LastIndex(clientID, "_"); i >= 0 {
+		if n, err := strconv.ParseInt(clientID[i+1:], 10, 64); err == nil && n > director.clientCount {
+			director.clientCount = n
+		}
+	}
+
+	client, _ := NewClient(director)
+	client.Id = clientID
+	client.connected = false
+	return client
+}
@@ -0,0 +1,104 @@
+package director_test
+
+import (
+	"fmt"
+	"github.com/malexanderboyd/pwr9-godr4ft/internal/director"
+	"github.com/malexanderboyd/pwr9-godr4ft/internal/director/models"
+	"github.com/malexanderboyd/pwr9-godr4ft/internal/game"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSnapshotRoundTrip(t *testing.T) {
+	dir, err := ioutil.TempDir("", "godr4ft")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	var cubeList strings.Builder
+	for i := 0; i < 24; i++ {
+		cubeList.WriteString(fmt.Sprintf("Cube Card %d\n", i))
+	}
+
+	options := game.GeneralOptions{
+		TotalPlayers: 4,
+		Type:         game.DRAFT,
+		Mode:         game.CUBE,
+		BotStrategy:  director.RandomBotStrategy,
+	}
+	options.GameOptions.Draft.Cube = game.DraftCubeOptions{
+		CardsPerPack: 2,
+		TotalPacks:   3,
+		CubeList:     cubeList.String(),
+	}
+
+	d := director.NewGameDirector(options, 9000, "snapshot_game")
+	if err := d.GetGameResources(); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	d.SeatBots(options.TotalPlayers)
+
+	path := filepath.Join(dir, "snapshot.json")
+	d.SaveSnapshot(path)
+
+	snapshot, err := director.LoadSnapshot(path)
+	if err != nil {
+		t.Fatalf("cannot load snapshot %v", err)
+	}
+
+	restored := director.NewGameDirectorFromSnapshot(snapshot, 9001)
+	if restored.GameId != d.GameId {
+		t.Errorf("expected game %s, got %s", d.GameId, restored.GameId)
+	}
+
+	if !reflect.DeepEqual(restored.RoundPacks(), d.RoundPacks()) {
+		t.Errorf("restored packs do not match the snapshotted packs")
+	}
+
+	if !reflect.DeepEqual(restored.Seats, d.Seats) {
+		t.Errorf("expected seats %v, got %v", d.Seats, restored.Seats)
+	}
+
+	if len(restored.Bots) != options.TotalPlayers {
+		t.Errorf("expected %d restored bots, got %d", options.TotalPlayers, len(restored.Bots))
+	}
+}
+
+func TestPicksAreSnapshotted(t *testing.T) {
+	dir, err := ioutil.TempDir("", "godr4ft")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	d, clients := director.NewSeatedCubeDraft(t, "pick_snapshot_game", game.GeneralOptions{}, 1)
+	client := clients[0]
+	path := filepath.Join(dir, "snapshot.json")
+	d.SetSnapshotPath(path, 10*time.Millisecond)
+	d.DealPack()
+	go d.Listen()
+	defer d.Finish()
+
+	var pack models.CardPack
+	_ = director.NextMessageOfType(t, client, models.RoundContent, 3*time.Second).Decode(&pack)
+	d.RunOnLoop(func() {
+		d.HandleClientMessage(client.Id, models.NewMessage(models.ChooseCard, &models.ChooseCardJson{UUID: pack.Pack[0].UUID, PackNumber: pack.PackNumber, Round: pack.Round}))
+	})
+	director.NextMessageOfType(t, client, models.PickConfirmed, 3*time.Second)
+
+	// a draft without deadlines still saves its picks, just not one by one
+	deadline := time.Now().Add(3 * time.Second)
+	for time.Now().Before(deadline) {
+		if snapshot, err := director.LoadSnapshot(path); err == nil && len(snapshot.PickLog) >= 2 {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Errorf("expected the picks to be snapshotted")
+}
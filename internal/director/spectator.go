@@ -0,0 +1,136 @@
+package director
+
+import (
+	"fmt"
+	"github.com/malexanderboyd/pwr9-godr4ft/internal"
+	"github.com/malexanderboyd/pwr9-godr4ft/internal/director/models"
+	"net/http"
+	"sort"
+	"time"
+)
+
+// newSpectator connects a websocket that watches the game, spectators get no
+// cookie since they have no seat to come back to.
+func (director *GameDirector) newSpectator(w http.ResponseWriter, r *http.Request) {
+	spectator, err := NewClient(director)
+	if err != nil {
+		director.Error(err)
+		return
+	}
+	spectator.spectator = true
+
+	ws, err := director.getUpgrader().Upgrade(w, r, nil)
+	if err != nil {
+		director.Error(err)
+		_, _ = fmt.Fprintf(w, err.Error())
+		return
+	}
+
+	spectator.Websocket = ws
+	director.AddNewClient(spectator)
+	go spectator.Listen()
+}
+
+func (director *GameDirector) isSpectator(clientID string) bool {
+	director.spectatorMu.Lock()
+	defer director.spectatorMu.Unlock()
+	_, ok := director.Spectators[clientID]
+	return ok
+}
+
+func (director *GameDirector) addSpectator(c *Client) {
+	internal.GetLogger().Debugw("Added spectator", "spectator", c.Id, "game", director.GameId)
+	director.spectatorMu.Lock()
+	director.Spectators[c.Id] = c
+	msgs := append([]*models.Message{}, director.messages...)
+	if director.spectatorView != nil {
+		msgs = append(msgs, director.spectatorView)
+	}
+	director.spectatorMu.Unlock()
+
+	go func() {
+		for _, msg := range msgs {
+			c.Write(msg)
+		}
+	}()
+}
+
+func (director *GameDirector) removeSpectator(c *Client) {
+	internal.GetLogger().Debugw("Removed spectator", "spectator", c.Id, "game", director.GameId)
+	director.spectatorMu.Lock()
+	defer director.spectatorMu.Unlock()
+	delete(director.Spectators, c.Id)
+}
+
+func (director *GameDirector) writeSpectators(msg *models.Message) {
+	director.spectatorMu.Lock()
+	defer director.spectatorMu.Unlock()
+	for _, spectator := range director.Spectators {
+		spectator.Write(msg)
+	}
+}
+
+func (director *GameDirector) getSpectatorDelay() time.Duration {
+	return time.Duration(director.options.SpectatorDelaySeconds) * time.Second
+}
+
+// getSpectatorViewMessage copies every seat's current pack and picks so the
+// view stays as it was when it is sent after the delay, callers hold pickMu
+func (director *GameDirector) getSpectatorViewMessage() *models.Message {
+	view := models.SpectatorViewJson{
+		PackNumber: director.packNumber + 1,
+	}
+	for playerID, seat := range director.Seats {
+		spectatorSeat := models.SpectatorSeatJson{
+			Seat:     seat,
+			PlayerId: playerID,
+		}
+		if head := director.getHeadPack(seat); head != nil {
+			spectatorSeat.Pack = append([]models.SetCard{}, head.Cards...)
+			spectatorSeat.Pick = head.Pick
+			spectatorSeat.Queued = len(director.packQueues[seat]) - 1
+		}
+		if client, ok := director.Clients[playerID]; ok {
+			spectatorSeat.Picks = client.getPool()
+		} else if bot, ok := director.Bots[playerID]; ok {
+			spectatorSeat.IsBot = true
+			spectatorSeat.Picks = append([]models.SetCard{}, bot.pool...)
+		}
+		view.Seats = append(view.Seats, spectatorSeat)
+	}
+	sort.Slice(view.Seats, func(i, j int) bool {
+		return view.Seats[i].Seat < view.Seats[j].Seat
+	})
+
+	return models.NewMessage(models.SpectatorView, &view)
+}
+
+// sendSpectatorView shows spectators every seat after the game's spectator
+// delay, so a cast of the draft can't be used to ghost it. Callers hold pickMu.
+func (director *GameDirector) sendSpectatorView() {
+	msg := director.getSpectatorViewMessage()
+	director.spectatorViewSeq++
+	seq := director.spectatorViewSeq
+	send := func() { director.showSpectatorView(seq, msg) }
+
+	if delay := director.getSpectatorDelay(); delay > 0 {
+		time.AfterFunc(delay, send)
+	} else {
+		send()
+	}
+}
+
+// showSpectatorView sends the view numbered seq, delayed views can fire out of
+// order and one older than the view on screen is dropped
+func (director *GameDirector) showSpectatorView(seq int, msg *models.Message) {
+	director.spectatorMu.Lock()
+	defer director.spectatorMu.Unlock()
+	if seq <= director.spectatorViewShown {
+		return
+	}
+	director.spectatorViewShown = seq
+	director.spectatorView = msg
+	for _, spectator := range director.Spectators {
+		spectator.Write(msg)
+	}
+}
@@ -0,0 +1,99 @@
+package director_test
+
+import (
+	"github.com/gorilla/websocket"
+	"github.com/malexanderboyd/pwr9-godr4ft/internal/director"
+	"github.com/malexanderboyd/pwr9-godr4ft/internal/director/models"
+	"github.com/malexanderboyd/pwr9-godr4ft/internal/game"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSpectatorSeesEverySeatAfterDelay(t *testing.T) {
+	d, _ := director.NewSeatedCubeDraft(t, "spectator_game", game.GeneralOptions{SpectatorDelaySeconds: 1}, 0)
+	d.DealPack()
+	go d.Listen()
+	defer d.Finish()
+
+	ts := httptest.NewServer(d.Handler())
+	defer ts.Close()
+	ws, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(ts.URL, "http")+"/ws?spectate=1", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ws.Close()
+
+	deadline := time.Now().Add(time.Second)
+	for d.SpectatorCount() == 0 {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected the spectator to be added")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	var players int
+	var host, botID string
+	d.RunOnLoop(func() {
+		players, host = len(d.Clients), d.Host()
+		for id := range d.Bots {
+			botID = id
+		}
+	})
+	if players != 0 || host != models.NoHostSentinel {
+		t.Errorf("expected a spectator to never be a player or host, got %d clients and host %s", players, host)
+	}
+	pickedAt := time.Now()
+	var pickErr error
+	d.RunOnLoop(func() { pickErr = d.ChooseCard(botID, 0, false) })
+	if pickErr != nil {
+		t.Fatalf("unexpected error %v", pickErr)
+	}
+
+	// the view of the deal can still be on its way, the one after the pick
+	// is the first to show a pick
+	var view models.SpectatorViewJson
+	for !hasPicks(view) {
+		if err := director.ReadUntil(t, ws, models.SpectatorView).Decode(&view); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if time.Since(pickedAt) < 900*time.Millisecond {
+		t.Errorf("expected the spectator view to be delayed, it came after %s", time.Since(pickedAt))
+	}
+	if len(view.Seats) != 2 {
+		t.Fatalf("expected every seat in the view, got %d", len(view.Seats))
+	}
+	for _, seat := range view.Seats {
+		if !seat.IsBot {
+			t.Errorf("expected seat %d to be a bot", seat.Seat)
+		}
+		if seat.PlayerId == botID && (len(seat.Picks) != 1 || len(seat.Pack) != 0) {
+			t.Errorf("expected the bot that picked to show its pick and no pack, got %+v", seat)
+		} else if seat.PlayerId != botID && (len(seat.Picks) != 0 || len(seat.Pack) != 3) {
+			t.Errorf("expected the other bot to still hold its pack, got %+v", seat)
+		}
+	}
+}
+
+func TestOlderSpectatorViewIsDropped(t *testing.T) {
+	d, _ := director.NewSeatedCubeDraft(t, "spectator_order_game", game.GeneralOptions{}, 0)
+	older := models.NewMessage(models.SpectatorView, &models.SpectatorViewJson{PackNumber: 1})
+	newer := models.NewMessage(models.SpectatorView, &models.SpectatorViewJson{PackNumber: 2})
+
+	// the newer view's timer fired first
+	d.ShowSpectatorView(2, newer)
+	d.ShowSpectatorView(1, older)
+	if d.SpectatorView() != newer {
+		t.Errorf("expected the older view to be dropped")
+	}
+}
+
+func hasPicks(view models.SpectatorViewJson) bool {
+	for _, seat := range view.Seats {
+		if len(seat.Picks) > 0 {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,84 @@
+package director
+
+import (
+	"encoding/json"
+	"github.com/malexanderboyd/pwr9-godr4ft/internal/director/models"
+	"net/http"
+	"time"
+)
+
+// serveStatus reports the game's phase, seats and timers as JSON
+func (director *GameDirector) serveStatus(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(director.GetStatus()); err != nil {
+		director.Error(err)
+	}
+}
+
+// GetStatus asks the Listen loop for the game's status, it owns the clients
+// and seats the status is built from
+func (director *GameDirector) GetStatus() *models.GameStatusJson {
+	reply := make(chan *models.GameStatusJson, 1)
+	select {
+	case director.statusCh <- reply:
+		return <-reply
+	case <-director.finishedCh:
+		return &models.GameStatusJson{
+			GameId: director.GameId,
+			Type:   director.options.Type.String(),
+			Mode:   director.options.Mode.String(),
+			Phase:  models.PhaseEnded,
+		}
+	}
+}
+
+// getStatus is only called from Listen
+func (director *GameDirector) getStatus() *models.GameStatusJson {
+	status := &models.GameStatusJson{
+		GameId:         director.GameId,
+		Type:           director.options.Type.String(),
+		Mode:           director.options.Mode.String(),
+		Correspondence: director.isCorrespondence(),
+		Phase:          director.phase,
+		TotalPacks:     director.totalPacks,
+		TimerPaused:    director.isRoundTimerPaused(),
+		OwesPick:       []string{},
+	}
+	if director.phase != models.PhaseLobby {
+		status.PackNumber = director.packNumber + 1
+	}
+	if director.phase == models.PhaseDeckbuilding && director.isDeckbuildingTimerEnabled() {
+		status.Timer = int(director.getDeckbuildingTimeRemaining() / time.Second)
+	}
+
+	director.pickMu.Lock()
+	defer director.pickMu.Unlock()
+	drafting := director.phase == models.PhaseDrafting
+	timed := false
+	for _, player := range director.getRoster().Players {
+		statusPlayer := models.GameStatusPlayerJson{
+			Id:        player.Id,
+			Name:      player.Name,
+			Seat:      player.Seat,
+			Bot:       player.Bot,
+			Connected: player.Connected,
+		}
+		if head := director.getHeadPack(player.Seat); drafting && player.Seat >= 0 && head != nil {
+			statusPlayer.Pick = head.Pick
+			statusPlayer.Queued = len(director.packQueues[player.Seat]) - 1
+			status.OwesPick = append(status.OwesPick, player.Id)
+			if status.Round == 0 || head.Pick < status.Round {
+				status.Round = head.Pick
+			}
+			if director.isTimerEnabled() {
+				statusPlayer.Timer = int(director.getPickTimeRemaining(player.Seat) / time.Second)
+				if !timed || statusPlayer.Timer < status.Timer {
+					status.Timer = statusPlayer.Timer
+					timed = true
+				}
+			}
+		}
+		status.Players = append(status.Players, statusPlayer)
+	}
+	return status
+}
@@ -0,0 +1,129 @@
+package director_test
+
+import (
+	"encoding/json"
+	"github.com/malexanderboyd/pwr9-godr4ft/internal/director"
+	"github.com/malexanderboyd/pwr9-godr4ft/internal/director/models"
+	"github.com/malexanderboyd/pwr9-godr4ft/internal/game"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestStatusShowsWhoOwesAPick(t *testing.T) {
+	d, clients := director.NewSeatedCubeDraft(t, "status_game", game.GeneralOptions{}, 1)
+	client := clients[0]
+	d.DealPack()
+	var botID string
+	for id := range d.Bots {
+		botID = id
+	}
+	if err := d.ChooseCard(botID, 0, false); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	go d.Listen()
+	defer d.Finish()
+
+	rec := httptest.NewRecorder()
+	d.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/status", nil))
+	var status models.GameStatusJson
+	if err := json.NewDecoder(rec.Body).Decode(&status); err != nil {
+		t.Fatalf("cannot decode status %v", err)
+	}
+	if status.GameId != "status_game" || status.Type != "draft" || status.Mode != "cube" || status.Phase != models.PhaseDrafting {
+		t.Errorf("expected the game's id, type, mode and phase, got %+v", status)
+	}
+	if status.PackNumber != 1 || status.Round != 1 || len(status.Players) != 2 {
+		t.Errorf("expected pick 1 of pack 1 with two players, got %+v", status)
+	}
+	if len(status.OwesPick) != 1 || status.OwesPick[0] != client.Id {
+		t.Errorf("expected only the player to owe a pick, got %v", status.OwesPick)
+	}
+	for _, player := range status.Players {
+		if player.Id == client.Id && (player.Pick != 1 || player.Queued != 1) {
+			t.Errorf("expected the player to hold pick 1 with a pack queued behind it, got %+v", player)
+		}
+	}
+}
+
+func TestReadyOnceTheGameIsLoaded(t *testing.T) {
+	server := director.NewGameServer(director.ServerConfig{GameId: "ready_game"})
+	ts := httptest.NewServer(server.Handler())
+	defer ts.Close()
+
+	for path, expected := range map[string]int{"/healthz": http.StatusOK, "/readyz": http.StatusServiceUnavailable} {
+		res, err := http.Get(ts.URL + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		res.Body.Close()
+		if res.StatusCode != expected {
+			t.Errorf("expected %s to answer %d before the game loads, got %d", path, expected, res.StatusCode)
+		}
+	}
+
+	var options game.GeneralOptions
+	server.AddGame(director.NewGameDirector(options, 9000, "ready_game"))
+	res, err := http.Get(ts.URL + "/readyz")
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		t.Errorf("expected the server to be ready once its game is loaded, got %d", res.StatusCode)
+	}
+}
+
+func TestNotReadyWhileGamesLoad(t *testing.T) {
+	release := make(chan bool)
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		options := game.GeneralOptions{TotalPlayers: 2, Type: game.DRAFT, Mode: game.CUBE}
+		options.GameOptions.Draft.Cube = game.DraftCubeOptions{CardsPerPack: 1, TotalPacks: 1, CubeList: "Black Lotus\nMox Pearl\n"}
+		_ = json.NewEncoder(w).Encode(options)
+	}))
+	defer api.Close()
+	dir, err := ioutil.TempDir("", "godr4ft")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	server := director.NewGameServer(director.ServerConfig{GameId: "loading_game", CardSource: director.NewHTTPCardSource(api.URL), SnapshotDir: dir, EndGracePeriod: time.Minute})
+	ts := httptest.NewServer(server.Handler())
+	defer ts.Close()
+	readyz := func() int {
+		res, err := http.Get(ts.URL + "/readyz")
+		if err != nil {
+			t.Fatal(err)
+		}
+		res.Body.Close()
+		return res.StatusCode
+	}
+
+	server.StartLoadingGames()
+	if code := readyz(); code != http.StatusServiceUnavailable {
+		t.Errorf("expected not ready while the game loads, got %d", code)
+	}
+	close(release)
+	deadline := time.Now().Add(3 * time.Second)
+	for readyz() != http.StatusOK {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected the server to be ready once the game loaded")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	d, _ := server.GetGame("loading_game")
+	d.Finish()
+	deadline = time.Now().Add(3 * time.Second)
+	for readyz() != http.StatusServiceUnavailable {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected an ended game's server not to be ready during its grace period")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -0,0 +1,179 @@
+package director
+
+import (
+	"errors"
+	"fmt"
+	"github.com/malexanderboyd/pwr9-godr4ft/internal"
+	"github.com/malexanderboyd/pwr9-godr4ft/internal/director/models"
+	"github.com/malexanderboyd/pwr9-godr4ft/internal/tournament"
+	"sort"
+)
+
+const DefaultSwissRounds = 3
+
+func (director *GameDirector) getSwissRounds() int {
+	if director.options.SwissRounds > 0 {
+		return director.options.SwissRounds
+	}
+	return DefaultSwissRounds
+}
+
+// getTournamentPlayers lists the seated players in seat order, bots don't
+// play matches. Seats a bot drafted from are skipped, so round 1 pairs each
+// player with the nearest player around the table rather than their literal
+// neighbour.
+func (director *GameDirector) getTournamentPlayers() []string {
+	var players []string
+	for clientID := range director.Seats {
+		if _, ok := director.Clients[clientID]; ok {
+			players = append(players, clientID)
+		}
+	}
+	sort.Slice(players, func(i, j int) bool {
+		return director.Seats[players[i]] < director.Seats[players[j]]
+	})
+	return players
+}
+
+// startTournament plays swiss rounds between the players once decks are
+// locked, a game with fewer than two players simply ends.
+func (director *GameDirector) startTournament() {
+	logger := internal.GetLogger()
+	tour, err := tournament.NewTournament(director.getTournamentPlayers(), director.getSwissRounds())
+	if err != nil {
+		logger.Infow("Skipping tournament", "game", director.GameId, "reason", err.Error())
+		go director.shutdown()
+		return
+	}
+
+	logger.Infow("Starting tournament", "game", director.GameId, "rounds", tour.TotalRounds)
+	director.tournamentMu.Lock()
+	defer director.tournamentMu.Unlock()
+	director.tournament = tour
+	director.phase = models.PhaseTournament
+	director.broadcast(models.NewMessage(models.PhaseChange, director.phase))
+	if err := director.pairNextRound(); err != nil {
+		director.Error(err)
+	}
+}
+
+func (director *GameDirector) pairNextRound() error {
+	matches, err := director.tournament.PairNextRound()
+	if err != nil {
+		return err
+	}
+
+	director.broadcast(models.NewMessage(models.Pairings, &models.PairingsJson{
+		Round:       director.tournament.CurrentRound(),
+		TotalRounds: director.tournament.TotalRounds,
+		Matches:     newMatchJsons(matches),
+	}))
+	director.saveSnapshot()
+	return nil
+}
+
+func (director *GameDirector) sendStandings() {
+	director.broadcast(models.NewMessage(models.Standings, &models.StandingsJson{
+		Round:     director.tournament.CurrentRound(),
+		Final:     director.tournament.IsOver(),
+		Standings: newStandingJsons(director.tournament.GetStandings()),
+	}))
+}
+
+// writeMatchUpdate shows both players of a match what each of them reported
+func (director *GameDirector) writeMatchUpdate(match *tournament.Match) {
+	for _, playerID := range []string{match.PlayerA, match.PlayerB} {
+		if client, ok := director.Clients[playerID]; ok {
+			client.Write(models.NewMessage(models.MatchUpdate, newMatchJson(match)))
+		}
+	}
+}
+
+// handleClientMatchResult records a player's side of their match, once
+// every match in the round is confirmed the standings go out and the next
+// round is paired.
+func (director *GameDirector) handleClientMatchResult(clientID string, resultMsg *models.MatchResultJson) error {
+	director.tournamentMu.Lock()
+	defer director.tournamentMu.Unlock()
+	if director.tournament == nil {
+		return errors.New(fmt.Sprintf("client %s reported a match result with no tournament running", clientID))
+	}
+
+	_, reportErr := director.tournament.ReportResult(clientID, tournament.Result{
+		Wins:   resultMsg.Wins,
+		Losses: resultMsg.Losses,
+		Draws:  resultMsg.Draws,
+	})
+	for _, match := range director.tournament.GetCurrentMatches() {
+		if match.PlayerA == clientID || match.PlayerB == clientID {
+			director.writeMatchUpdate(match)
+		}
+	}
+	if reportErr != nil {
+		return reportErr
+	}
+	director.saveSnapshot()
+
+	if !director.tournament.IsRoundComplete() {
+		return nil
+	}
+	director.sendStandings()
+	if director.tournament.IsOver() {
+		internal.GetLogger().Infow("Tournament over", "game", director.GameId)
+		go director.shutdown()
+		return nil
+	}
+	return director.pairNextRound()
+}
+
+func newMatchResultJson(result tournament.Result) models.MatchResultJson {
+	return models.MatchResultJson{Wins: result.Wins, Losses: result.Losses, Draws: result.Draws}
+}
+
+// newMatchJson copies a match into its wire form, the tournament keeps
+// changing the match as results come in
+func newMatchJson(match *tournament.Match) *models.MatchJson {
+	matchJson := &models.MatchJson{
+		Round:     match.Round,
+		Table:     match.Table,
+		PlayerA:   match.PlayerA,
+		PlayerB:   match.PlayerB,
+		Reports:   make(map[string]models.MatchResultJson),
+		Confirmed: match.Confirmed,
+	}
+	for playerID, report := range match.Reports {
+		matchJson.Reports[playerID] = newMatchResultJson(report)
+	}
+	if match.Result != nil {
+		result := newMatchResultJson(*match.Result)
+		matchJson.Result = &result
+	}
+	return matchJson
+}
+
+func newMatchJsons(matches []*tournament.Match) []*models.MatchJson {
+	var matchJsons []*models.MatchJson
+	for _, match := range matches {
+		matchJsons = append(matchJsons, newMatchJson(match))
+	}
+	return matchJsons
+}
+
+func newStandingJsons(standings []tournament.Standing) []models.StandingJson {
+	var standingJsons []models.StandingJson
+	for _, standing := range standings {
+		standingJsons = append(standingJsons, models.StandingJson{
+			Rank:           standing.Rank,
+			PlayerId:       standing.PlayerId,
+			MatchPoints:    standing.MatchPoints,
+			MatchesPlayed:  standing.MatchesPlayed,
+			GamePoints:     standing.GamePoints,
+			GamesPlayed:    standing.GamesPlayed,
+			MatchWinPct:    standing.MatchWinPct,
+			GameWinPct:     standing.GameWinPct,
+			OppMatchWinPct: standing.OppMatchWinPct,
+			OppGameWinPct:  standing.OppGameWinPct,
+		})
+	}
+	return standingJsons
+}
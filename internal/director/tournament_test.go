@@ -0,0 +1,100 @@
+package director_test
+
+import (
+	"github.com/malexanderboyd/pwr9-godr4ft/internal/director"
+	"github.com/malexanderboyd/pwr9-godr4ft/internal/director/models"
+	"github.com/malexanderboyd/pwr9-godr4ft/internal/game"
+	"testing"
+	"time"
+)
+
+func TestTournamentRound(t *testing.T) {
+	options := game.GeneralOptions{TotalPlayers: 2, Type: game.SEALED, Mode: game.CUBE, SwissRounds: 1}
+	d := director.NewGameDirector(options, 9000, "tournament_game")
+	alice, _ := director.NewClient(d)
+	bob, _ := director.NewClient(d)
+	d.Clients[alice.Id] = alice
+	d.Clients[bob.Id] = bob
+	d.SeatClients(2)
+	d.SetPhase(models.PhaseDeckbuilding)
+	go d.Listen()
+	defer d.Finish()
+
+	d.RunOnLoop(d.StartTournament)
+	var pairings models.PairingsJson
+	_ = director.NextMessageOfType(t, alice, models.Pairings, 3*time.Second).Decode(&pairings)
+	if pairings.Round != 1 || pairings.TotalRounds != 1 || len(pairings.Matches) != 1 {
+		t.Fatalf("expected one match in round 1 of 1, got %+v", pairings)
+	}
+	match := pairings.Matches[0]
+	if match.PlayerA == match.PlayerB || (match.PlayerA != alice.Id && match.PlayerA != bob.Id) || (match.PlayerB != alice.Id && match.PlayerB != bob.Id) {
+		t.Errorf("expected the seats to play each other, got %s vs %s", match.PlayerA, match.PlayerB)
+	}
+
+	d.RunOnLoop(func() {
+		d.HandleClientMessage(alice.Id, models.NewMessage(models.MatchResult, &models.MatchResultJson{Wins: 2, Losses: 1}))
+	})
+	var update models.MatchJson
+	_ = director.NextMessageOfType(t, bob, models.MatchUpdate, 3*time.Second).Decode(&update)
+	if update.Confirmed || update.Reports[alice.Id].Wins != 2 {
+		t.Errorf("expected bob to see alice's unconfirmed report, got %+v", update)
+	}
+
+	d.RunOnLoop(func() {
+		d.HandleClientMessage(bob.Id, models.NewMessage(models.MatchResult, &models.MatchResultJson{Wins: 1, Losses: 2}))
+	})
+	_ = director.NextMessageOfType(t, alice, models.MatchUpdate, 3*time.Second).Decode(&update)
+	_ = director.NextMessageOfType(t, alice, models.MatchUpdate, 3*time.Second).Decode(&update)
+	// the result is from PlayerA's side of the match
+	expectedWins := 2
+	if update.PlayerA == bob.Id {
+		expectedWins = 1
+	}
+	if !update.Confirmed || update.Result == nil || update.Result.Wins != expectedWins {
+		t.Errorf("expected the agreed 2-1 to confirm the match, got %+v", update)
+	}
+
+	var standings models.StandingsJson
+	_ = director.NextMessageOfType(t, alice, models.Standings, 3*time.Second).Decode(&standings)
+	if !standings.Final || len(standings.Standings) != 2 || standings.Standings[0].PlayerId != alice.Id || standings.Standings[0].MatchPoints != 3 {
+		t.Errorf("expected alice to win the final standings, got %+v", standings)
+	}
+	director.NextMessageOfType(t, alice, models.GameEnd, 3*time.Second)
+}
+
+func TestKickDuringTournamentIsRejected(t *testing.T) {
+	options := game.GeneralOptions{TotalPlayers: 2, Type: game.SEALED, Mode: game.CUBE, SwissRounds: 1}
+	d := director.NewGameDirector(options, 9000, "tournament_kick_game")
+	alice, _ := director.NewClient(d)
+	bob, _ := director.NewClient(d)
+	d.Clients[alice.Id] = alice
+	d.Clients[bob.Id] = bob
+	d.SeatClients(2)
+	d.SetPhase(models.PhaseDeckbuilding)
+	d.SetHost(alice.Id)
+	go d.Listen()
+	defer d.Finish()
+
+	d.RunOnLoop(d.StartTournament)
+	director.NextMessageOfType(t, alice, models.Pairings, 3*time.Second)
+
+	d.RunOnLoop(func() {
+		d.HandleClientMessage(alice.Id, models.NewMessage(models.KickPlayer, &models.KickPlayerJson{PlayerId: bob.Id}))
+	})
+	var errMsg models.ErrorJson
+	_ = director.NextMessageOfType(t, alice, models.Error, 3*time.Second).Decode(&errMsg)
+	if errMsg.Code != models.ErrWrongPhase {
+		t.Errorf("expected a kick mid round to be rejected, got %+v", errMsg)
+	}
+
+	// bob is still there to confirm the match and finish the round
+	d.RunOnLoop(func() {
+		d.HandleClientMessage(alice.Id, models.NewMessage(models.MatchResult, &models.MatchResultJson{Wins: 2}))
+		d.HandleClientMessage(bob.Id, models.NewMessage(models.MatchResult, &models.MatchResultJson{Losses: 2}))
+	})
+	var standings models.StandingsJson
+	_ = director.NextMessageOfType(t, alice, models.Standings, 3*time.Second).Decode(&standings)
+	if !standings.Final {
+		t.Errorf("expected the round to finish with bob still playing, got %+v", standings)
+	}
+}
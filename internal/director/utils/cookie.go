@@ -5,13 +5,13 @@ import (
 	"time"
 )
 
-func CreateDraftClientIDCookieHeader(clientID, cookieName string) http.Header {
+func CreateDraftClientIDCookieHeader(clientID, cookieName, path string, lifetime time.Duration) http.Header {
 	var clientIDHeader = http.Header{}
 	clientIdCookie := &http.Cookie{
 		Name:    cookieName,
 		Value:   clientID,
-		Path:    "/",
-		Expires: time.Now().Add(time.Minute * 30),
+		Path:    path,
+		Expires: time.Now().Add(lifetime),
 	}
 	if v := clientIdCookie.String(); v != "" {
 		clientIDHeader.Add("Set-Cookie", v)
@@ -7,6 +7,16 @@ const (
 	SEALED Type = 2
 )
 
+func (t Type) String() string {
+	switch t {
+	case DRAFT:
+		return "draft"
+	case SEALED:
+		return "sealed"
+	}
+	return "unknown"
+}
+
 type Mode int
 
 const (
@@ -15,6 +25,18 @@ const (
 	CHAOS   Mode = 3
 )
 
+func (m Mode) String() string {
+	switch m {
+	case REGULAR:
+		return "regular"
+	case CUBE:
+		return "cube"
+	case CHAOS:
+		return "chaos"
+	}
+	return "unknown"
+}
+
 type DraftRegularOptions struct {
 	TotalPacks    int               `json:"totalPacks"`
 	SelectedPacks map[string]string `json:"selectedPacks"`
@@ -72,4 +94,13 @@ type GeneralOptions struct {
 	Mode         Mode    `json:"gameMode"`
 	Type         Type    `json:"gameType"`
 	GameOptions  ModeMap `json:"options"`
+	// how bots draft the seats no player takes, ie: "rarity", "color" or "random"
+	BotStrategy string `json:"botStrategy"`
+	// rounds of swiss played after deckbuilding, 0 uses the default
+	SwissRounds int `json:"swissRounds"`
+	// how far behind the draft spectators see packs and picks
+	SpectatorDelaySeconds int `json:"spectatorDelaySeconds"`
+	// hours a correspondence drafter has for each pick before the server picks
+	// for them, 0 is a live draft
+	PickDeadlineHours int `json:"pickDeadlineHours"`
 }
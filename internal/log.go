@@ -12,9 +12,11 @@ type logger struct {
 
 var Logger *logger
 
+// loggerOnce builds Logger the first time any goroutine asks for it
+var loggerOnce sync.Once
+
 func GetLogger() *logger {
-	var nonce sync.Once
-	nonce.Do(func() {
+	loggerOnce.Do(func() {
 		ENV := os.Getenv("NODE_ENV")
 		Logger = initLogger(ENV)
 	})
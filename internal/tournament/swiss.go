@@ -0,0 +1,384 @@
+package tournament
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// ByePlayer is the opponent of a player who sits a round out
+const ByePlayer = ""
+
+const (
+	matchWinPoints  = 3
+	matchDrawPoints = 1
+	gameWinPoints   = 3
+	gameDrawPoints  = 1
+	// match and game win percentages never go below a third, per the MTG tournament rules
+	minimumWinPercentage = 1.0 / 3.0
+)
+
+type Result struct {
+	Wins   int `json:"wins"`
+	Losses int `json:"losses"`
+	Draws  int `json:"draws"`
+}
+
+func (r Result) reversed() Result {
+	return Result{Wins: r.Losses, Losses: r.Wins, Draws: r.Draws}
+}
+
+func (r Result) isValid() bool {
+	return r.Wins >= 0 && r.Losses >= 0 && r.Draws >= 0 && r.Wins <= 2 && r.Losses <= 2 && r.Wins+r.Losses+r.Draws <= 3
+}
+
+type Match struct {
+	Round   int    `json:"round"`
+	Table   int    `json:"table"`
+	PlayerA string `json:"playerA"`
+	PlayerB string `json:"playerB"`
+	// results as each player reported them, from their own side
+	Reports map[string]Result `json:"reports"`
+	// PlayerA's result once both players agree
+	Result    *Result `json:"result"`
+	Confirmed bool    `json:"confirmed"`
+}
+
+func (m *Match) IsBye() bool {
+	return m.PlayerB == ByePlayer
+}
+
+func (m *Match) hasPlayer(playerId string) bool {
+	return m.PlayerA == playerId || m.PlayerB == playerId
+}
+
+func (m *Match) getOpponent(playerId string) string {
+	if m.PlayerA == playerId {
+		return m.PlayerB
+	}
+	return m.PlayerA
+}
+
+// getPlayerResult is the match from playerId's side
+func (m *Match) getPlayerResult(playerId string) Result {
+	if m.PlayerA == playerId {
+		return *m.Result
+	}
+	return m.Result.reversed()
+}
+
+type Standing struct {
+	Rank           int     `json:"rank"`
+	PlayerId       string  `json:"playerId"`
+	MatchPoints    int     `json:"matchPoints"`
+	MatchesPlayed  int     `json:"matchesPlayed"`
+	GamePoints     int     `json:"gamePoints"`
+	GamesPlayed    int     `json:"gamesPlayed"`
+	MatchWinPct    float64 `json:"matchWinPercentage"`
+	GameWinPct     float64 `json:"gameWinPercentage"`
+	OppMatchWinPct float64 `json:"opponentsMatchWinPercentage"`
+	OppGameWinPct  float64 `json:"opponentsGameWinPercentage"`
+}
+
+// Tournament runs a Swiss event, players are listed in seat order so the
+// first round pairs neighbours like a booster draft.
+type Tournament struct {
+	Players     []string   `json:"players"`
+	TotalRounds int        `json:"totalRounds"`
+	Rounds      [][]*Match `json:"rounds"`
+}
+
+func NewTournament(playersInSeatOrder []string, totalRounds int) (*Tournament, error) {
+	if len(playersInSeatOrder) < 2 {
+		return nil, errors.New("a tournament needs at least two players")
+	}
+	if totalRounds < 1 {
+		return nil, errors.New(fmt.Sprintf("invalid number of rounds %d", totalRounds))
+	}
+	return &Tournament{
+		Players:     playersInSeatOrder,
+		TotalRounds: totalRounds,
+	}, nil
+}
+
+func (t *Tournament) CurrentRound() int {
+	return len(t.Rounds)
+}
+
+func (t *Tournament) GetCurrentMatches() []*Match {
+	if len(t.Rounds) == 0 {
+		return nil
+	}
+	return t.Rounds[len(t.Rounds)-1]
+}
+
+func (t *Tournament) IsRoundComplete() bool {
+	for _, match := range t.GetCurrentMatches() {
+		if !match.Confirmed {
+			return false
+		}
+	}
+	return true
+}
+
+func (t *Tournament) IsOver() bool {
+	return t.CurrentRound() == t.TotalRounds && t.IsRoundComplete()
+}
+
+// PairNextRound pairs neighbours in the first round, after that players are
+// paired down the standings without rematches.
+func (t *Tournament) PairNextRound() ([]*Match, error) {
+	if !t.IsRoundComplete() {
+		return nil, errors.New(fmt.Sprintf("round %d is not over", t.CurrentRound()))
+	}
+	if t.CurrentRound() >= t.TotalRounds {
+		return nil, errors.New("the tournament is over")
+	}
+
+	var order []string
+	if t.CurrentRound() == 0 {
+		order = t.Players
+	} else {
+		for _, standing := range t.GetStandings() {
+			order = append(order, standing.PlayerId)
+		}
+	}
+
+	round := t.CurrentRound() + 1
+	var pairs [][2]string
+	if t.CurrentRound() == 0 {
+		pairs = pairInOrder(order)
+	} else {
+		var bye string
+		if len(order)%2 == 1 {
+			bye = t.getByePlayer(order)
+			order = remove(order, bye)
+		}
+		played := t.getPlayedPairs()
+		var ok bool
+		if pairs, ok = pairWithoutRematches(order, played); !ok {
+			// everyone has played everyone, allow rematches
+			pairs = pairInOrder(order)
+		}
+		if bye != "" {
+			pairs = append(pairs, [2]string{bye, ByePlayer})
+		}
+	}
+
+	var matches []*Match
+	for i, pair := range pairs {
+		match := &Match{
+			Round:   round,
+			Table:   i + 1,
+			PlayerA: pair[0],
+			PlayerB: pair[1],
+			Reports: make(map[string]Result),
+		}
+		if match.IsBye() {
+			match.Result = &Result{Wins: 2}
+			match.Confirmed = true
+		}
+		matches = append(matches, match)
+	}
+	t.Rounds = append(t.Rounds, matches)
+	return matches, nil
+}
+
+// ReportResult records a player's result for their current match from their
+// side. It reports true once both players have agreed on the same result.
+func (t *Tournament) ReportResult(playerId string, result Result) (bool, error) {
+	if !result.isValid() {
+		return false, errors.New(fmt.Sprintf("invalid result %d-%d-%d", result.Wins, result.Losses, result.Draws))
+	}
+
+	var match *Match
+	for _, m := range t.GetCurrentMatches() {
+		if m.hasPlayer(playerId) {
+			match = m
+		}
+	}
+	if match == nil {
+		return false, errors.New(fmt.Sprintf("player %s has no match this round", playerId))
+	}
+	if match.Confirmed {
+		return true, errors.New(fmt.Sprintf("the result of player %s's match is already confirmed", playerId))
+	}
+
+	match.Reports[playerId] = result
+	opponentReport, ok := match.Reports[match.getOpponent(playerId)]
+	if !ok {
+		return false, nil
+	}
+	if opponentReport != result.reversed() {
+		return false, errors.New(fmt.Sprintf("player %s reported %d-%d-%d but their opponent reported %d-%d-%d",
+			playerId, result.Wins, result.Losses, result.Draws, opponentReport.Losses, opponentReport.Wins, opponentReport.Draws))
+	}
+
+	playerAResult := match.Reports[match.PlayerA]
+	match.Result = &playerAResult
+	match.Confirmed = true
+	return true, nil
+}
+
+func (t *Tournament) getPlayedPairs() map[[2]string]bool {
+	played := make(map[[2]string]bool)
+	for _, round := range t.Rounds {
+		for _, match := range round {
+			played[[2]string{match.PlayerA, match.PlayerB}] = true
+			played[[2]string{match.PlayerB, match.PlayerA}] = true
+		}
+	}
+	return played
+}
+
+// getByePlayer is the lowest ranked player who has not had a bye yet
+func (t *Tournament) getByePlayer(order []string) string {
+	hadBye := make(map[string]bool)
+	for _, round := range t.Rounds {
+		for _, match := range round {
+			if match.IsBye() {
+				hadBye[match.PlayerA] = true
+			}
+		}
+	}
+	for i := len(order) - 1; i >= 0; i-- {
+		if !hadBye[order[i]] {
+			return order[i]
+		}
+	}
+	return order[len(order)-1]
+}
+
+// GetStandings ranks players by match points then OMW%, GW% and OGW%
+func (t *Tournament) GetStandings() []Standing {
+	standings := make(map[string]*Standing)
+	opponents := make(map[string][]string)
+	for _, playerId := range t.Players {
+		standings[playerId] = &Standing{PlayerId: playerId}
+	}
+
+	for _, round := range t.Rounds {
+		for _, match := range round {
+			if !match.Confirmed {
+				continue
+			}
+			for _, playerId := range []string{match.PlayerA, match.PlayerB} {
+				if playerId == ByePlayer {
+					continue
+				}
+				standing := standings[playerId]
+				result := match.getPlayerResult(playerId)
+				standing.MatchesPlayed++
+				if result.Wins > result.Losses {
+					standing.MatchPoints += matchWinPoints
+				} else if result.Wins == result.Losses {
+					standing.MatchPoints += matchDrawPoints
+				}
+				standing.GamePoints += result.Wins*gameWinPoints + result.Draws*gameDrawPoints
+				standing.GamesPlayed += result.Wins + result.Losses + result.Draws
+				if !match.IsBye() {
+					opponents[playerId] = append(opponents[playerId], match.getOpponent(playerId))
+				}
+			}
+		}
+	}
+
+	for _, standing := range standings {
+		standing.MatchWinPct = getWinPercentage(standing.MatchPoints, matchWinPoints*standing.MatchesPlayed)
+		standing.GameWinPct = getWinPercentage(standing.GamePoints, gameWinPoints*standing.GamesPlayed)
+	}
+	for playerId, standing := range standings {
+		var matchWinPcts, gameWinPcts float64
+		for _, opponent := range opponents[playerId] {
+			matchWinPcts += standings[opponent].MatchWinPct
+			gameWinPcts += standings[opponent].GameWinPct
+		}
+		if n := len(opponents[playerId]); n > 0 {
+			standing.OppMatchWinPct = matchWinPcts / float64(n)
+			standing.OppGameWinPct = gameWinPcts / float64(n)
+		}
+	}
+
+	// ties past every tiebreaker keep seat order so standings are stable
+	seatOrder := make(map[string]int)
+	for i, playerId := range t.Players {
+		seatOrder[playerId] = i
+	}
+
+	var ranked []Standing
+	for _, playerId := range t.Players {
+		ranked = append(ranked, *standings[playerId])
+	}
+	sort.SliceStable(ranked, func(i, j int) bool {
+		a, b := ranked[i], ranked[j]
+		if a.MatchPoints != b.MatchPoints {
+			return a.MatchPoints > b.MatchPoints
+		}
+		if a.OppMatchWinPct != b.OppMatchWinPct {
+			return a.OppMatchWinPct > b.OppMatchWinPct
+		}
+		if a.GameWinPct != b.GameWinPct {
+			return a.GameWinPct > b.GameWinPct
+		}
+		if a.OppGameWinPct != b.OppGameWinPct {
+			return a.OppGameWinPct > b.OppGameWinPct
+		}
+		return seatOrder[a.PlayerId] < seatOrder[b.PlayerId]
+	})
+	for i := range ranked {
+		ranked[i].Rank = i + 1
+	}
+	return ranked
+}
+
+func getWinPercentage(points, possible int) float64 {
+	if possible == 0 {
+		return minimumWinPercentage
+	}
+	pct := float64(points) / float64(possible)
+	if pct < minimumWinPercentage {
+		return minimumWinPercentage
+	}
+	return pct
+}
+
+// pairInOrder pairs players two at a time, an odd player out gets a bye
+func pairInOrder(order []string) [][2]string {
+	var pairs [][2]string
+	for i := 0; i+1 < len(order); i += 2 {
+		pairs = append(pairs, [2]string{order[i], order[i+1]})
+	}
+	if len(order)%2 == 1 {
+		pairs = append(pairs, [2]string{order[len(order)-1], ByePlayer})
+	}
+	return pairs
+}
+
+// pairWithoutRematches pairs the highest ranked unpaired player with the next
+// highest they have not played, backtracking when that strands someone.
+func pairWithoutRematches(order []string, played map[[2]string]bool) ([][2]string, bool) {
+	if len(order) == 0 {
+		return nil, true
+	}
+	first := order[0]
+	for i := 1; i < len(order); i++ {
+		if played[[2]string{first, order[i]}] {
+			continue
+		}
+		rest := remove(remove(order, first), order[i])
+		if pairs, ok := pairWithoutRematches(rest, played); ok {
+			return append([][2]string{{first, order[i]}}, pairs...), true
+		}
+	}
+	return nil, false
+}
+
+func remove(order []string, playerId string) []string {
+	var rest []string
+	for _, p := range order {
+		if p != playerId {
+			rest = append(rest, p)
+		}
+	}
+	return rest
+}
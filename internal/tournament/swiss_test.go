@@ -0,0 +1,128 @@
+package tournament_test
+
+import (
+	"github.com/malexanderboyd/pwr9-godr4ft/internal/tournament"
+	"testing"
+)
+
+func report(t *testing.T, tour *tournament.Tournament, playerA, playerB string, wins, losses, draws int) {
+	if _, err := tour.ReportResult(playerA, tournament.Result{Wins: wins, Losses: losses, Draws: draws}); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	confirmed, err := tour.ReportResult(playerB, tournament.Result{Wins: losses, Losses: wins, Draws: draws})
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if !confirmed {
+		t.Fatalf("expected %s vs %s to be confirmed", playerA, playerB)
+	}
+}
+
+func TestFirstRoundPairsNeighbours(t *testing.T) {
+	tour, err := tournament.NewTournament([]string{"a", "b", "c", "d", "e"}, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	matches, err := tour.PairNextRound()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(matches) != 3 {
+		t.Fatalf("expected 3 matches, got %d", len(matches))
+	}
+	if matches[0].PlayerA != "a" || matches[0].PlayerB != "b" || matches[1].PlayerA != "c" || matches[1].PlayerB != "d" {
+		t.Errorf("expected neighbours to be paired, got %v %v", matches[0], matches[1])
+	}
+	if !matches[2].IsBye() || matches[2].PlayerA != "e" || !matches[2].Confirmed {
+		t.Errorf("expected e to get a confirmed bye, got %v", matches[2])
+	}
+	if _, err := tour.PairNextRound(); err == nil {
+		t.Errorf("expected an error pairing before the round is over")
+	}
+}
+
+func TestReportResultNeedsBothPlayersToAgree(t *testing.T) {
+	tour, _ := tournament.NewTournament([]string{"a", "b"}, 1)
+	_, _ = tour.PairNextRound()
+
+	confirmed, err := tour.ReportResult("a", tournament.Result{Wins: 2, Losses: 1})
+	if err != nil || confirmed {
+		t.Fatalf("expected a pending report, got %v %v", confirmed, err)
+	}
+	if _, err := tour.ReportResult("b", tournament.Result{Wins: 2, Losses: 1}); err == nil {
+		t.Errorf("expected an error when both players claim the win")
+	}
+	if _, err := tour.ReportResult("a", tournament.Result{Wins: 3}); err == nil {
+		t.Errorf("expected an error for an impossible result")
+	}
+	confirmed, err = tour.ReportResult("b", tournament.Result{Wins: 1, Losses: 2})
+	if err != nil || !confirmed {
+		t.Fatalf("expected a confirmed result, got %v %v", confirmed, err)
+	}
+	if !tour.IsOver() {
+		t.Errorf("expected a one round tournament to be over")
+	}
+}
+
+func TestSwissAvoidsRematchesAndRanksByTiebreakers(t *testing.T) {
+	tour, _ := tournament.NewTournament([]string{"a", "b", "c", "d"}, 3)
+	_, _ = tour.PairNextRound()
+	report(t, tour, "a", "b", 2, 0, 0)
+	report(t, tour, "c", "d", 2, 1, 0)
+
+	matches, err := tour.PairNextRound()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// winners play winners
+	if matches[0].PlayerA != "a" || matches[0].PlayerB != "c" || matches[1].PlayerA != "b" || matches[1].PlayerB != "d" {
+		t.Errorf("expected a-c and b-d, got %s-%s and %s-%s", matches[0].PlayerA, matches[0].PlayerB, matches[1].PlayerA, matches[1].PlayerB)
+	}
+	report(t, tour, "a", "c", 2, 0, 0)
+	report(t, tour, "b", "d", 1, 1, 1)
+
+	matches, _ = tour.PairNextRound()
+	for _, match := range matches {
+		if (match.PlayerA == "a" && (match.PlayerB == "b" || match.PlayerB == "c")) || (match.PlayerA == "c" && match.PlayerB == "d") {
+			t.Errorf("unexpected rematch %s-%s", match.PlayerA, match.PlayerB)
+		}
+	}
+	report(t, tour, matches[0].PlayerA, matches[0].PlayerB, 2, 0, 0)
+	report(t, tour, matches[1].PlayerA, matches[1].PlayerB, 0, 2, 0)
+	if !tour.IsOver() {
+		t.Fatalf("expected the tournament to be over after 3 rounds")
+	}
+
+	standings := tour.GetStandings()
+	if standings[0].PlayerId != "a" || standings[0].MatchPoints != 9 || standings[0].Rank != 1 {
+		t.Errorf("expected a to finish first on 9 points, got %+v", standings[0])
+	}
+	for _, standing := range standings {
+		if standing.MatchWinPct < 1.0/3.0 || standing.GameWinPct < 1.0/3.0 {
+			t.Errorf("expected win percentages to be floored at a third, got %+v", standing)
+		}
+	}
+}
+
+func TestByeRotates(t *testing.T) {
+	tour, _ := tournament.NewTournament([]string{"a", "b", "c"}, 3)
+	byes := make(map[string]int)
+	for round := 0; round < 3; round++ {
+		matches, err := tour.PairNextRound()
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, match := range matches {
+			if match.IsBye() {
+				byes[match.PlayerA]++
+			} else {
+				report(t, tour, match.PlayerA, match.PlayerB, 2, 0, 0)
+			}
+		}
+	}
+	for _, player := range []string{"a", "b", "c"} {
+		if byes[player] != 1 {
+			t.Errorf("expected every player to get one bye, got %v", byes)
+		}
+	}
+}